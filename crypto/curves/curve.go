@@ -2,7 +2,9 @@ package curves
 
 import (
 	"crypto/elliptic"
-	"reflect"
+	"fmt"
+	"math/big"
+	"sync"
 
 	"github.com/decred/dcrd/dcrec/edwards/v2"
 	"github.com/decred/dcrd/dcrec/secp256k1/v2"
@@ -11,28 +13,150 @@ import (
 const (
 	Secp256k1 string = "secp256k1"
 	Ed25519   string = "ed25519"
+	P256      string = "P-256"
+	Stark     string = "stark"
+
+	Ristretto255 string = "ristretto255"
 )
 
-var curveMap map[string]elliptic.Curve
+// Definition everything a curve supplies to be used by DKG, VSS, the schnorr proofs and ECPoint encoding
+type Definition interface {
+	// Name unique curve name, also used in the ECPoint JSON encoding
+	Name() string
+	Curve() elliptic.Curve
+	// Order prime order of the group generated by the base point
+	Order() *big.Int
+	// Encode serialize a point, compressed or uncompressed
+	Encode(X, Y *big.Int, compressed bool) ([]byte, error)
+	// Decode parse a compressed or uncompressed point, the point must be on the curve
+	Decode(data []byte) (*big.Int, *big.Int, error)
+	// HashToScalar hash data to a scalar mod Order
+	HashToScalar(data ...[]byte) *big.Int
+	// Identity coordinates of the neutral element
+	Identity() (*big.Int, *big.Int)
+}
 
-// only support ecdsa、ed25519
+var (
+	registryLock sync.RWMutex
+	registry     []Definition
+	builtin      int // the first builtin definitions of registry are registered by init
+)
+
+// only support ecdsa(secp256k1, P-256, stark)、ed25519、ristretto255 by default, more curves are added by Register
 func init() {
-	curveMap = map[string]elliptic.Curve{
-		Secp256k1: secp256k1.S256(),
-		Ed25519:   edwards.Edwards(),
+	for _, def := range []Definition{
+		NewWeierstrassDefinition(Secp256k1, secp256k1.S256(), big.NewInt(0)),
+		NewEdwardsDefinition(Ed25519, edwards.Edwards()),
+		NewWeierstrassDefinition(P256, elliptic.P256(), big.NewInt(-3)),
+		NewWeierstrassDefinition(Stark, StarkCurve(), big.NewInt(1)),
+		&ristrettoDefinition{curve: ristrettoCurve},
+	} {
+		if err := Register(def); err != nil {
+			panic(err)
+		}
 	}
+	builtin = len(registry)
+}
+
+// Register add a curve, the name and the curve must not be registered yet
+func Register(def Definition) error {
+	if def == nil || def.Curve() == nil || len(def.Name()) == 0 {
+		return fmt.Errorf("register curve parameters error")
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	for _, d := range registry {
+		if d.Name() == def.Name() {
+			return fmt.Errorf("curve %s already registered", def.Name())
+		}
+		if sameCurve(d.Curve(), def.Curve()) {
+			return fmt.Errorf("curve %s already registered as %s", def.Name(), d.Name())
+		}
+	}
+	registry = append(registry, def)
+	return nil
+}
+
+// Lookup definition of a registered curve
+func Lookup(curve elliptic.Curve) (Definition, bool) {
+	if curve == nil {
+		return nil, false
+	}
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	for _, d := range registry {
+		if d.Curve() == curve {
+			return d, true
+		}
+	}
+	// same parameters, different instance
+	for _, d := range registry {
+		if sameCurve(d.Curve(), curve) {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+// LookupByName definition of a registered curve
+func LookupByName(curveName string) (Definition, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	for _, d := range registry {
+		if d.Name() == curveName {
+			return d, true
+		}
+	}
+	return nil, false
 }
 
 func GetCurveByName(curveName string) (elliptic.Curve, bool) {
-	val, ok := curveMap[curveName]
-	return val, ok
+	def, ok := LookupByName(curveName)
+	if !ok {
+		return nil, false
+	}
+	return def.Curve(), true
 }
 
 func GetCurveName(curve elliptic.Curve) string {
-	for name, e := range curveMap {
-		if reflect.TypeOf(curve) == reflect.TypeOf(e) {
-			return name
+	def, ok := Lookup(curve)
+	if !ok {
+		return ""
+	}
+	return def.Name()
+}
+
+// IsBuiltin whether the curve is registered by default, not added by Register
+func IsBuiltin(curve elliptic.Curve) bool {
+	def, ok := Lookup(curve)
+	if !ok {
+		return false
+	}
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	for _, d := range registry[:builtin] {
+		if d == def {
+			return true
 		}
 	}
-	return ""
+	return false
+}
+
+// HashToScalar hash data to a scalar of a registered curve
+func HashToScalar(curve elliptic.Curve, data ...[]byte) (*big.Int, error) {
+	def, ok := Lookup(curve)
+	if !ok {
+		return nil, fmt.Errorf("curve is not registered")
+	}
+	return def.HashToScalar(data...), nil
+}
+
+// sameCurve compare the domain parameters, curves of the same type are told apart
+// the name tells apart groups on the same curve, eg: ed25519 and ristretto255
+func sameCurve(c1, c2 elliptic.Curve) bool {
+	p1, p2 := c1.Params(), c2.Params()
+	if p1 == nil || p2 == nil {
+		return false
+	}
+	return p1.Name == p2.Name && p1.P.Cmp(p2.P) == 0 && p1.N.Cmp(p2.N) == 0 && p1.Gx.Cmp(p2.Gx) == 0 && p1.Gy.Cmp(p2.Gy) == 0
 }
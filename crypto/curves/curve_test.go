@@ -1,9 +1,12 @@
 package curves
 
 import (
+	"bytes"
+	"crypto/elliptic"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/decred/dcrd/dcrec/edwards/v2"
@@ -61,3 +64,174 @@ func TestPubKeyToPoint(t *testing.T) {
 		fmt.Println(point2)
 	}
 }
+
+func TestEncode(t *testing.T) {
+	for _, curve := range []elliptic.Curve{secp256k1.S256(), edwards.Edwards(), elliptic.P256(), StarkCurve(), RistrettoCurve()} {
+		x := crypto.RandomNum(curve.Params().N)
+		point := ScalarToPoint(curve, x)
+		for _, compressed := range []bool{true, false} {
+			data, err := point.Encode(compressed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := DecodePoint(curve, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !p.Equals(point) {
+				t.Fatalf("%s decode mismatch", GetCurveName(curve))
+			}
+		}
+	}
+
+	point := ScalarToPoint(secp256k1.S256(), big.NewInt(7))
+	data, _ := point.Encode(true)
+	if hex.EncodeToString(data) != point.PointToEcdsaPubKey() {
+		t.Fatal("secp256k1 encoding mismatch")
+	}
+	point = ScalarToPoint(edwards.Edwards(), big.NewInt(7))
+	data, _ = point.Encode(true)
+	if hex.EncodeToString(data) != point.PointToEd25519PubKey() {
+		t.Fatal("ed25519 encoding mismatch")
+	}
+	point = ScalarToPoint(elliptic.P256(), big.NewInt(7))
+	data, _ = point.Encode(true)
+	if !bytes.Equal(data, elliptic.MarshalCompressed(elliptic.P256(), point.X, point.Y)) {
+		t.Fatal("P-256 encoding mismatch")
+	}
+
+	// identity
+	if !ScalarToPoint(edwards.Edwards(), big.NewInt(0)).IsIdentity() || point.IsIdentity() {
+		t.Fatal("identity error")
+	}
+	if _, err := DecodePoint(secp256k1.S256(), []byte{0}); err == nil {
+		t.Fatal("identity must be rejected")
+	}
+}
+
+func TestStarkScalarMult(t *testing.T) {
+	curve := StarkCurve()
+	N := curve.Params().N
+	k, m := crypto.RandomNum(N), crypto.RandomNum(N)
+	kx, ky := curve.ScalarBaseMult(k.Bytes())
+	mx, my := curve.ScalarBaseMult(m.Bytes())
+	x, y := curve.Add(kx, ky, mx, my)
+	sum := new(big.Int).Mod(new(big.Int).Add(k, m), N)
+	// leading zero bytes do not change the result
+	sx, sy := curve.ScalarBaseMult(append([]byte{0, 0}, sum.Bytes()...))
+	if x.Cmp(sx) != 0 || y.Cmp(sy) != 0 {
+		t.Fatal("stark scalar mult error")
+	}
+	if x, y := curve.ScalarBaseMult(N.Bytes()); x.Sign() != 0 || y.Sign() != 0 {
+		t.Fatal("N*G must be the identity")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	// both curves have the type *elliptic.CurveParams
+	p384, p521 := elliptic.P384().Params(), elliptic.P521().Params()
+	if err := Register(NewWeierstrassDefinition("P-384", p384, big.NewInt(-3))); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { unregister("P-384") })
+	if err := Register(NewWeierstrassDefinition("P-521", p521, big.NewInt(-3))); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { unregister("P-521") })
+	if err := Register(NewWeierstrassDefinition("P-384", elliptic.P224(), big.NewInt(-3))); err == nil {
+		t.Fatal("duplicate name must be rejected")
+	}
+	if err := Register(NewWeierstrassDefinition("secp256k1-copy", secp256k1.S256(), big.NewInt(0))); err == nil {
+		t.Fatal("duplicate curve must be rejected")
+	}
+	if GetCurveName(p384) != "P-384" || GetCurveName(p521) != "P-521" || GetCurveName(elliptic.P256()) != P256 {
+		t.Fatal("curve name error")
+	}
+	if IsBuiltin(p521) || !IsBuiltin(secp256k1.S256()) || !IsBuiltin(StarkCurve()) {
+		t.Fatal("builtin curve error")
+	}
+
+	point := ScalarToPoint(p521, crypto.RandomNum(p521.N))
+	payload, err := json.Marshal(point)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := ECPoint{}
+	if err = json.Unmarshal(payload, &p); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Equals(point) || GetCurveName(p.Curve) != "P-521" {
+		t.Fatal("json round trip error")
+	}
+	data, _ := point.Encode(true)
+	if !bytes.Equal(data, elliptic.MarshalCompressed(p521, point.X, point.Y)) {
+		t.Fatal("P-521 encoding mismatch")
+	}
+
+	s1, _ := HashToScalar(p521, []byte("message"))
+	s2, _ := HashToScalar(p384, []byte("message"))
+	if s1.Cmp(p521.N) >= 0 || s2.Cmp(p384.N) >= 0 {
+		t.Fatal("hash to scalar out of range")
+	}
+	// inputs are length framed
+	s1, _ = HashToScalar(p521, []byte("mess"), []byte("age"))
+	s2, _ = HashToScalar(p521, []byte("messa"), []byte("ge"))
+	if s1.Cmp(s2) == 0 {
+		t.Fatal("hash to scalar must separate the inputs")
+	}
+}
+
+// unregister remove a curve registered by a test, so the tests can run more than once
+func unregister(name string) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	for i, d := range registry {
+		if d.Name() == name {
+			registry = append(registry[:i], registry[i+1:]...)
+			return
+		}
+	}
+}
+
+func TestRistretto(t *testing.T) {
+	// RFC 9496 A.1 multiples of the generator
+	multiples := []string{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76",
+		"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+		"94741f5d5d52755ece4f23f044ee27d5d1ea1e2bd196b462166b16152a9d0259",
+	}
+	curve := RistrettoCurve()
+	def, _ := Lookup(curve)
+	for i, m := range multiples {
+		point := ScalarToPoint(curve, big.NewInt(int64(i)))
+		data, err := def.Encode(point.X, point.Y, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(data) != m {
+			t.Fatal("ristretto255 encoding mismatch", i, hex.EncodeToString(data))
+		}
+		X, Y, err := def.Decode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if X.Cmp(point.X) != 0 || Y.Cmp(point.Y) != 0 {
+			t.Fatal("ristretto255 decoding mismatch", i)
+		}
+	}
+	// RFC 9496 A.2 invalid encodings: non-canonical, negative field element
+	for _, bad := range []string{
+		"00ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"0100000000000000000000000000000000000000000000000000000000000000",
+	} {
+		data, _ := hex.DecodeString(bad)
+		if _, err := DecodePoint(curve, data); err == nil {
+			t.Fatal("invalid ristretto255 encoding accepted", bad)
+		}
+	}
+	// same base point as ed25519, but a different curve
+	if GetCurveName(curve) != Ristretto255 || GetCurveName(edwards.Edwards()) != Ed25519 {
+		t.Fatal("curve name error")
+	}
+}
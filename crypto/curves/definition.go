@@ -0,0 +1,176 @@
+package curves
+
+import (
+	"crypto/elliptic"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+)
+
+type weierstrassDefinition struct {
+	name  string
+	curve elliptic.Curve
+	a     *big.Int
+}
+
+// NewWeierstrassDefinition short Weierstrass curve y^2 = x^3 + a*x + b with SEC1 point encoding,
+// the identity is (0, 0) as in crypto/elliptic and is encoded as a single zero byte
+func NewWeierstrassDefinition(name string, curve elliptic.Curve, a *big.Int) Definition {
+	if curve == nil || a == nil {
+		return nil
+	}
+	return &weierstrassDefinition{name: name, curve: curve, a: new(big.Int).Set(a)}
+}
+
+func (w *weierstrassDefinition) Name() string {
+	return w.name
+}
+
+func (w *weierstrassDefinition) Curve() elliptic.Curve {
+	return w.curve
+}
+
+func (w *weierstrassDefinition) Order() *big.Int {
+	return w.curve.Params().N
+}
+
+func (w *weierstrassDefinition) Encode(X, Y *big.Int, compressed bool) ([]byte, error) {
+	if X == nil || Y == nil {
+		return nil, fmt.Errorf("encode point parameters error")
+	}
+	if X.Sign() == 0 && Y.Sign() == 0 {
+		return []byte{0}, nil
+	}
+	if !w.curve.IsOnCurve(X, Y) {
+		return nil, fmt.Errorf("encode point not on curve %s", w.name)
+	}
+	size := (w.curve.Params().BitSize + 7) / 8
+	if !compressed {
+		data := make([]byte, 1+2*size)
+		data[0] = 4
+		X.FillBytes(data[1 : 1+size])
+		Y.FillBytes(data[1+size:])
+		return data, nil
+	}
+	data := make([]byte, 1+size)
+	data[0] = byte(2 + Y.Bit(0))
+	X.FillBytes(data[1:])
+	return data, nil
+}
+
+func (w *weierstrassDefinition) Decode(data []byte) (*big.Int, *big.Int, error) {
+	if len(data) == 1 && data[0] == 0 {
+		return big.NewInt(0), big.NewInt(0), nil
+	}
+	params := w.curve.Params()
+	size := (params.BitSize + 7) / 8
+	var X, Y *big.Int
+	switch {
+	case len(data) == 1+2*size && data[0] == 4:
+		X = new(big.Int).SetBytes(data[1 : 1+size])
+		Y = new(big.Int).SetBytes(data[1+size:])
+	case len(data) == 1+size && (data[0] == 2 || data[0] == 3):
+		X = new(big.Int).SetBytes(data[1:])
+		if X.Cmp(params.P) >= 0 {
+			return nil, nil, fmt.Errorf("decode point x out of range")
+		}
+		// y^2 = x^3 + a*x + b
+		y2 := new(big.Int).Exp(X, big.NewInt(3), params.P)
+		y2.Add(y2, new(big.Int).Mul(w.a, X))
+		y2.Add(y2, params.B)
+		y2.Mod(y2, params.P)
+		Y = new(big.Int).ModSqrt(y2, params.P)
+		if Y == nil {
+			return nil, nil, fmt.Errorf("decode point not on curve %s", w.name)
+		}
+		if Y.Bit(0) != uint(data[0]&1) {
+			Y.Sub(params.P, Y)
+		}
+	default:
+		return nil, nil, fmt.Errorf("decode point length error")
+	}
+	if X.Cmp(params.P) >= 0 || Y.Cmp(params.P) >= 0 || !w.curve.IsOnCurve(X, Y) {
+		return nil, nil, fmt.Errorf("decode point not on curve %s", w.name)
+	}
+	return X, Y, nil
+}
+
+func (w *weierstrassDefinition) HashToScalar(data ...[]byte) *big.Int {
+	return hashToScalar(w.Order(), data...)
+}
+
+func (w *weierstrassDefinition) Identity() (*big.Int, *big.Int) {
+	return big.NewInt(0), big.NewInt(0)
+}
+
+type edwardsDefinition struct {
+	name  string
+	curve *edwards.TwistedEdwardsCurve
+}
+
+// NewEdwardsDefinition edwards25519 with the 32-byte RFC 8032 encoding, compressed and uncompressed are the same,
+// the identity is (0, 1)
+func NewEdwardsDefinition(name string, curve *edwards.TwistedEdwardsCurve) Definition {
+	if curve == nil {
+		return nil
+	}
+	return &edwardsDefinition{name: name, curve: curve}
+}
+
+func (e *edwardsDefinition) Name() string {
+	return e.name
+}
+
+func (e *edwardsDefinition) Curve() elliptic.Curve {
+	return e.curve
+}
+
+func (e *edwardsDefinition) Order() *big.Int {
+	return e.curve.Params().N
+}
+
+func (e *edwardsDefinition) Encode(X, Y *big.Int, compressed bool) ([]byte, error) {
+	if X == nil || Y == nil || !e.curve.IsOnCurve(X, Y) {
+		return nil, fmt.Errorf("encode point not on curve %s", e.name)
+	}
+	publicKey := edwards.PublicKey{Curve: e.curve, X: X, Y: Y}
+	return publicKey.Serialize(), nil
+}
+
+func (e *edwardsDefinition) Decode(data []byte) (*big.Int, *big.Int, error) {
+	if len(data) != 32 {
+		return nil, nil, fmt.Errorf("decode point length error")
+	}
+	publicKey, err := edwards.ParsePubKey(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !e.curve.IsOnCurve(publicKey.X, publicKey.Y) {
+		return nil, nil, fmt.Errorf("decode point not on curve %s", e.name)
+	}
+	return publicKey.X, publicKey.Y, nil
+}
+
+func (e *edwardsDefinition) HashToScalar(data ...[]byte) *big.Int {
+	return hashToScalar(e.Order(), data...)
+}
+
+func (e *edwardsDefinition) Identity() (*big.Int, *big.Int) {
+	return big.NewInt(0), big.NewInt(1)
+}
+
+// hashToScalar SHA-512(len(d1) || d1 || len(d2) || d2 ...) mod n, lengths are 8-byte big-endian,
+// so the split of the inputs is unambiguous, the 512-bit digest keeps the bias negligible up to 256-bit orders
+func hashToScalar(n *big.Int, data ...[]byte) *big.Int {
+	h := sha512.New()
+	length := make([]byte, 8)
+	for _, d := range data {
+		binary.BigEndian.PutUint64(length, uint64(len(d)))
+		h.Write(length)
+		h.Write(d)
+	}
+	return new(big.Int).Mod(new(big.Int).SetBytes(h.Sum(nil)), n)
+}
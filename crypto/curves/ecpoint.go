@@ -103,6 +103,48 @@ func (p *ECPoint) UnmarshalJSON(payload []byte) error {
 	return nil
 }
 
+// Encode serialize the point with the encoding of its curve
+func (p *ECPoint) Encode(compressed bool) ([]byte, error) {
+	if p == nil || p.Curve == nil {
+		return nil, fmt.Errorf("encode point parameters error")
+	}
+	def, ok := Lookup(p.Curve)
+	if !ok {
+		return nil, fmt.Errorf("encode point error, curves are not supported")
+	}
+	return def.Encode(p.X, p.Y, compressed)
+}
+
+// DecodePoint parse a point with the encoding of the curve, the identity is rejected
+func DecodePoint(curve elliptic.Curve, data []byte) (*ECPoint, error) {
+	def, ok := Lookup(curve)
+	if !ok {
+		return nil, fmt.Errorf("decode point error, curves are not supported")
+	}
+	X, Y, err := def.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	IX, IY := def.Identity()
+	if X.Cmp(IX) == 0 && Y.Cmp(IY) == 0 {
+		return nil, fmt.Errorf("decode point is the identity")
+	}
+	return NewECPoint(curve, X, Y)
+}
+
+// IsIdentity whether the point is the neutral element of its curve
+func (p *ECPoint) IsIdentity() bool {
+	if p == nil || p.X == nil || p.Y == nil {
+		return false
+	}
+	def, ok := Lookup(p.Curve)
+	if !ok {
+		return false
+	}
+	X, Y := def.Identity()
+	return p.X.Cmp(X) == 0 && p.Y.Cmp(Y) == 0
+}
+
 func (p *ECPoint) PointToEcdsaPubKey() string {
 	publicKey := secp256k1.PublicKey{Curve: p.Curve, X: p.X, Y: p.Y}
 	return hex.EncodeToString(publicKey.SerializeCompressed())
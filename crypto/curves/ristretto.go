@@ -0,0 +1,200 @@
+package curves
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+)
+
+// ristretto255 constants of RFC 9496 over p = 2^255 - 19, a = -1
+var (
+	ristrettoP      = edwards.Edwards().P
+	ristrettoD      = new(big.Int).Mod(edwards.Edwards().D, ristrettoP)
+	ristrettoSqrtM1 = new(big.Int).Exp(big.NewInt(2), new(big.Int).Rsh(new(big.Int).Sub(ristrettoP, big.NewInt(1)), 2), ristrettoP)
+	// 1/sqrt(a - d)
+	ristrettoInvSqrtAMinusD = invSqrtAMinusD()
+
+	ristrettoCurve = newRistrettoCurve()
+)
+
+func invSqrtAMinusD() *big.Int {
+	_, r := sqrtRatioM1(big.NewInt(1), feSub(big.NewInt(-1), ristrettoD))
+	return r
+}
+
+// ristretto255Curve edwards25519 restricted to the prime order subgroup, points are encoded with ristretto255
+// every ristretto255 element is represented by its unique point of order l
+type ristretto255Curve struct {
+	*edwards.TwistedEdwardsCurve
+	params *elliptic.CurveParams
+}
+
+// RistrettoCurve the ristretto255 group of RFC 9496, used by sr25519
+func RistrettoCurve() elliptic.Curve {
+	return ristrettoCurve
+}
+
+func newRistrettoCurve() *ristretto255Curve {
+	ed := edwards.Edwards()
+	params := *ed.Params()
+	params.Name = Ristretto255
+	return &ristretto255Curve{TwistedEdwardsCurve: ed, params: &params}
+}
+
+func (c *ristretto255Curve) Params() *elliptic.CurveParams {
+	return c.params
+}
+
+// IsOnCurve on edwards25519 and of order l, points with a torsion component are refused
+func (c *ristretto255Curve) IsOnCurve(x, y *big.Int) bool {
+	if x == nil || y == nil || x.Sign() < 0 || x.Cmp(ristrettoP) >= 0 || y.Sign() < 0 || y.Cmp(ristrettoP) >= 0 {
+		return false
+	}
+	if !c.TwistedEdwardsCurve.IsOnCurve(x, y) {
+		return false
+	}
+	lx, ly := c.TwistedEdwardsCurve.ScalarMult(x, y, c.params.N.Bytes())
+	return lx != nil && lx.Sign() == 0 && ly.Cmp(big.NewInt(1)) == 0
+}
+
+type ristrettoDefinition struct {
+	curve *ristretto255Curve
+}
+
+func (r *ristrettoDefinition) Name() string {
+	return Ristretto255
+}
+
+func (r *ristrettoDefinition) Curve() elliptic.Curve {
+	return r.curve
+}
+
+func (r *ristrettoDefinition) Order() *big.Int {
+	return r.curve.params.N
+}
+
+// Encode 32-byte ristretto255 encoding, compressed and uncompressed are the same
+func (r *ristrettoDefinition) Encode(X, Y *big.Int, compressed bool) ([]byte, error) {
+	if X == nil || Y == nil || !r.curve.IsOnCurve(X, Y) {
+		return nil, fmt.Errorf("encode point not on curve %s", Ristretto255)
+	}
+	one := big.NewInt(1)
+	T := feMul(X, Y)
+	u1 := feMul(feAdd(one, Y), feSub(one, Y))
+	u2 := T
+	_, invSqrt := sqrtRatioM1(one, feMul(u1, feMul(u2, u2)))
+	den1 := feMul(invSqrt, u1)
+	den2 := feMul(invSqrt, u2)
+	zInv := feMul(feMul(den1, den2), T)
+
+	x, y, denInv := X, Y, den2
+	if isNegative(feMul(T, zInv)) {
+		x = feMul(Y, ristrettoSqrtM1)
+		y = feMul(X, ristrettoSqrtM1)
+		denInv = feMul(den1, ristrettoInvSqrtAMinusD)
+	}
+	if isNegative(feMul(x, zInv)) {
+		y = feSub(big.NewInt(0), y)
+	}
+	s := feAbs(feMul(denInv, feSub(one, y)))
+
+	data := make([]byte, 32)
+	s.FillBytes(data)
+	reverse(data)
+	return data, nil
+}
+
+// Decode ristretto255 decoding, returns the point of order l of the element
+func (r *ristrettoDefinition) Decode(data []byte) (*big.Int, *big.Int, error) {
+	if len(data) != 32 {
+		return nil, nil, fmt.Errorf("decode point length error")
+	}
+	le := make([]byte, 32)
+	copy(le, data)
+	reverse(le)
+	s := new(big.Int).SetBytes(le)
+	if s.Cmp(ristrettoP) >= 0 || isNegative(s) {
+		return nil, nil, fmt.Errorf("decode point non-canonical encoding")
+	}
+	one := big.NewInt(1)
+	ss := feMul(s, s)
+	u1 := feSub(one, ss)
+	u2 := feAdd(one, ss)
+	u2Sqr := feMul(u2, u2)
+	v := feSub(feSub(big.NewInt(0), feMul(ristrettoD, feMul(u1, u1))), u2Sqr)
+	wasSquare, invSqrt := sqrtRatioM1(one, feMul(v, u2Sqr))
+	denX := feMul(invSqrt, u2)
+	denY := feMul(feMul(invSqrt, denX), v)
+	x := feAbs(feMul(feMul(big.NewInt(2), s), denX))
+	y := feMul(u1, denY)
+	if !wasSquare || isNegative(feMul(x, y)) || y.Sign() == 0 {
+		return nil, nil, fmt.Errorf("decode point invalid encoding")
+	}
+
+	// the decoded point may carry 4-torsion T, l = 1 mod 4 so l*(P+T) = T
+	ed := r.curve.TwistedEdwardsCurve
+	tx, ty := ed.ScalarMult(x, y, r.curve.params.N.Bytes())
+	x, y = ed.Add(x, y, feSub(big.NewInt(0), tx), ty)
+	if !r.curve.IsOnCurve(x, y) {
+		return nil, nil, fmt.Errorf("decode point not on curve %s", Ristretto255)
+	}
+	return x, y, nil
+}
+
+func (r *ristrettoDefinition) HashToScalar(data ...[]byte) *big.Int {
+	return hashToScalar(r.Order(), data...)
+}
+
+func (r *ristrettoDefinition) Identity() (*big.Int, *big.Int) {
+	return big.NewInt(0), big.NewInt(1)
+}
+
+// sqrtRatioM1 RFC 9496 SQRT_RATIO_M1, returns whether u/v is square and the non-negative root of u/v or i*u/v
+func sqrtRatioM1(u, v *big.Int) (bool, *big.Int) {
+	v3 := feMul(feMul(v, v), v)
+	v7 := feMul(feMul(v3, v3), v)
+	exp := new(big.Int).Rsh(new(big.Int).Sub(ristrettoP, big.NewInt(5)), 3)
+	r := feMul(feMul(u, v3), new(big.Int).Exp(feMul(u, v7), exp, ristrettoP))
+	check := feMul(v, feMul(r, r))
+
+	uNeg := feSub(big.NewInt(0), u)
+	correctSign := check.Cmp(new(big.Int).Mod(u, ristrettoP)) == 0
+	flippedSign := check.Cmp(uNeg) == 0
+	flippedSignI := check.Cmp(feMul(uNeg, ristrettoSqrtM1)) == 0
+	if flippedSign || flippedSignI {
+		r = feMul(r, ristrettoSqrtM1)
+	}
+	return correctSign || flippedSign, feAbs(r)
+}
+
+func feAdd(a, b *big.Int) *big.Int {
+	return new(big.Int).Mod(new(big.Int).Add(a, b), ristrettoP)
+}
+
+func feSub(a, b *big.Int) *big.Int {
+	return new(big.Int).Mod(new(big.Int).Sub(a, b), ristrettoP)
+}
+
+func feMul(a, b *big.Int) *big.Int {
+	return new(big.Int).Mod(new(big.Int).Mul(a, b), ristrettoP)
+}
+
+// isNegative least significant bit of the canonical encoding
+func isNegative(a *big.Int) bool {
+	return new(big.Int).Mod(a, ristrettoP).Bit(0) == 1
+}
+
+func feAbs(a *big.Int) *big.Int {
+	if isNegative(a) {
+		return feSub(big.NewInt(0), a)
+	}
+	return new(big.Int).Mod(a, ristrettoP)
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
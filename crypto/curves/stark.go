@@ -0,0 +1,127 @@
+package curves
+
+import (
+	"crypto/elliptic"
+	"math/big"
+)
+
+var starkCurve = newStarkCurve()
+
+// weierstrassCurve affine arithmetic on y^2 = x^3 + a*x + b, crypto/elliptic only implements a = -3
+// the identity is (0, 0) as in crypto/elliptic
+type weierstrassCurve struct {
+	params *elliptic.CurveParams
+	a      *big.Int
+}
+
+// StarkCurve the STARK curve of StarkNet, y^2 = x^3 + x + b over p = 2^251 + 17*2^192 + 1
+func StarkCurve() elliptic.Curve {
+	return starkCurve
+}
+
+func newStarkCurve() *weierstrassCurve {
+	params := &elliptic.CurveParams{Name: Stark, BitSize: 252}
+	params.P, _ = new(big.Int).SetString("800000000000011000000000000000000000000000000000000000000000001", 16)
+	params.N, _ = new(big.Int).SetString("800000000000010ffffffffffffffffb781126dcae7b2321e66a241adc64d2f", 16)
+	params.B, _ = new(big.Int).SetString("6f21413efbe40de150e596d72f7a8c5609ad26c15c915c1f4cdfcb99cee9e89", 16)
+	params.Gx, _ = new(big.Int).SetString("1ef15c18599971b7beced415a40f0c7deacfd9b0d1819e03d723d8bc943cfca", 16)
+	params.Gy, _ = new(big.Int).SetString("5668060aa49730b7be4801df46ec62de53ecd11abe43a32873000c36e8dc1f", 16)
+	return &weierstrassCurve{params: params, a: big.NewInt(1)}
+}
+
+func (c *weierstrassCurve) Params() *elliptic.CurveParams {
+	return c.params
+}
+
+func (c *weierstrassCurve) IsOnCurve(x, y *big.Int) bool {
+	P := c.params.P
+	if x == nil || y == nil || x.Sign() < 0 || x.Cmp(P) >= 0 || y.Sign() < 0 || y.Cmp(P) >= 0 {
+		return false
+	}
+	// y^2 = x^3 + a*x + b
+	lhs := new(big.Int).Mul(y, y)
+	lhs.Mod(lhs, P)
+	rhs := new(big.Int).Exp(x, big.NewInt(3), P)
+	rhs.Add(rhs, new(big.Int).Mul(c.a, x))
+	rhs.Add(rhs, c.params.B)
+	rhs.Mod(rhs, P)
+	return lhs.Cmp(rhs) == 0
+}
+
+func (c *weierstrassCurve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	if isInfinity(x1, y1) {
+		return new(big.Int).Set(x2), new(big.Int).Set(y2)
+	}
+	if isInfinity(x2, y2) {
+		return new(big.Int).Set(x1), new(big.Int).Set(y1)
+	}
+	P := c.params.P
+	if x1.Cmp(x2) == 0 {
+		if y1.Cmp(y2) == 0 {
+			return c.Double(x1, y1)
+		}
+		// P + (-P)
+		return new(big.Int), new(big.Int)
+	}
+	// m = (y2 - y1) / (x2 - x1)
+	num := new(big.Int).Sub(y2, y1)
+	den := new(big.Int).Sub(x2, x1)
+	den.Mod(den, P)
+	m := num.Mul(num, new(big.Int).ModInverse(den, P))
+	m.Mod(m, P)
+	return c.affine(m, x1, y1, x2)
+}
+
+func (c *weierstrassCurve) Double(x1, y1 *big.Int) (*big.Int, *big.Int) {
+	if isInfinity(x1, y1) || y1.Sign() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+	P := c.params.P
+	// m = (3*x^2 + a) / 2y
+	num := new(big.Int).Mul(x1, x1)
+	num.Mul(num, big.NewInt(3))
+	num.Add(num, c.a)
+	den := new(big.Int).Lsh(y1, 1)
+	den.Mod(den, P)
+	m := num.Mul(num, new(big.Int).ModInverse(den, P))
+	m.Mod(m, P)
+	return c.affine(m, x1, y1, x1)
+}
+
+// affine x3 = m^2 - x1 - x2, y3 = m*(x1 - x3) - y1
+func (c *weierstrassCurve) affine(m, x1, y1, x2 *big.Int) (*big.Int, *big.Int) {
+	P := c.params.P
+	x3 := new(big.Int).Mul(m, m)
+	x3.Sub(x3, x1)
+	x3.Sub(x3, x2)
+	x3.Mod(x3, P)
+	y3 := new(big.Int).Sub(x1, x3)
+	y3.Mul(y3, m)
+	y3.Sub(y3, y1)
+	y3.Mod(y3, P)
+	return x3, y3
+}
+
+// ScalarMult Montgomery ladder over all len(k)*8 bits, every bit costs one Add and one Double,
+// so the sequence of operations does not depend on the secret bits, the big.Int field arithmetic
+// and the special cases of Add are still variable time, do not use it where timing is observable
+func (c *weierstrassCurve) ScalarMult(x1, y1 *big.Int, k []byte) (*big.Int, *big.Int) {
+	// r[0] = m*P, r[1] = (m+1)*P for the bits m processed so far
+	r := [2][2]*big.Int{{new(big.Int), new(big.Int)}, {new(big.Int).Set(x1), new(big.Int).Set(y1)}}
+	for i := 0; i < len(k)*8; i++ {
+		bit := (k[i/8] >> (7 - uint(i%8))) & 1
+		sx, sy := c.Add(r[0][0], r[0][1], r[1][0], r[1][1])
+		dx, dy := c.Double(r[bit][0], r[bit][1])
+		r[1-bit] = [2]*big.Int{sx, sy}
+		r[bit] = [2]*big.Int{dx, dy}
+	}
+	return r[0][0], r[0][1]
+}
+
+func (c *weierstrassCurve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	return c.ScalarMult(c.params.Gx, c.params.Gy, k)
+}
+
+func isInfinity(x, y *big.Int) bool {
+	return x.Sign() == 0 && y.Sign() == 0
+}
@@ -0,0 +1,35 @@
+package merlin
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestTranscript(t *testing.T) {
+	// merlin equivalence_simple test vector
+	transcript := NewTranscript("test protocol")
+	transcript.AppendMessage([]byte("some label"), []byte("some data"))
+	challenge := transcript.ChallengeBytes([]byte("challenge"), 32)
+	if hex.EncodeToString(challenge) != "d5a21972d0d5fe320c0d263fac7fffb8145aa640af6e9bca177c03c7efcf0615" {
+		t.Fatal("merlin challenge mismatch", hex.EncodeToString(challenge))
+	}
+}
+
+func TestTranscriptComplex(t *testing.T) {
+	// merlin equivalence_complex test vector, crosses many STROBE blocks
+	transcript := NewTranscript("test protocol")
+	data := make([]byte, 1024)
+	for i := range data {
+		data[i] = 99
+	}
+	transcript.AppendMessage([]byte("step1"), []byte("some data"))
+	var challenge []byte
+	for i := 0; i < 32; i++ {
+		challenge = transcript.ChallengeBytes([]byte("challenge"), 32)
+		transcript.AppendMessage([]byte("bigdata"), data)
+		transcript.AppendMessage([]byte("challengedata"), challenge)
+	}
+	if hex.EncodeToString(challenge) != "a8c933f54fae76e3f9bea93648c1308e7dfa2152dd51674ff3ca438351cf003c" {
+		t.Fatal("merlin challenge mismatch", hex.EncodeToString(challenge))
+	}
+}
@@ -0,0 +1,175 @@
+package merlin
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// strobe128 the STROBE-128 subset merlin needs: meta-AD, AD, PRF and KEY, over Keccak-f[1600]
+const (
+	strobeR = 166
+
+	flagI = 1
+	flagA = 1 << 1
+	flagC = 1 << 2
+	flagT = 1 << 3
+	flagM = 1 << 4
+	flagK = 1 << 5
+)
+
+type strobe128 struct {
+	state    [200]byte
+	pos      int
+	posBegin int
+	curFlags byte
+}
+
+func newStrobe128(protocolLabel []byte) *strobe128 {
+	s := &strobe128{}
+	copy(s.state[:], []byte{1, strobeR + 2, 1, 0, 1, 96})
+	copy(s.state[6:], "STROBEv1.0.2")
+	keccakF1600(&s.state)
+	s.metaAD(protocolLabel, false)
+	return s
+}
+
+func (s *strobe128) clone() *strobe128 {
+	c := *s
+	return &c
+}
+
+func (s *strobe128) metaAD(data []byte, more bool) {
+	s.beginOp(flagM|flagA, more)
+	s.absorb(data)
+}
+
+func (s *strobe128) ad(data []byte, more bool) {
+	s.beginOp(flagA, more)
+	s.absorb(data)
+}
+
+func (s *strobe128) prf(data []byte, more bool) {
+	s.beginOp(flagI|flagA|flagC, more)
+	s.squeeze(data)
+}
+
+func (s *strobe128) key(data []byte, more bool) {
+	s.beginOp(flagA|flagC, more)
+	s.overwrite(data)
+}
+
+func (s *strobe128) runF() {
+	s.state[s.pos] ^= byte(s.posBegin)
+	s.state[s.pos+1] ^= 0x04
+	s.state[strobeR+1] ^= 0x80
+	keccakF1600(&s.state)
+	s.pos = 0
+	s.posBegin = 0
+}
+
+func (s *strobe128) absorb(data []byte) {
+	for _, b := range data {
+		s.state[s.pos] ^= b
+		s.pos++
+		if s.pos == strobeR {
+			s.runF()
+		}
+	}
+}
+
+func (s *strobe128) overwrite(data []byte) {
+	for _, b := range data {
+		s.state[s.pos] = b
+		s.pos++
+		if s.pos == strobeR {
+			s.runF()
+		}
+	}
+}
+
+func (s *strobe128) squeeze(data []byte) {
+	for i := range data {
+		data[i] = s.state[s.pos]
+		s.state[s.pos] = 0
+		s.pos++
+		if s.pos == strobeR {
+			s.runF()
+		}
+	}
+}
+
+// beginOp more continues the previous operation with the same flags
+func (s *strobe128) beginOp(flags byte, more bool) {
+	if more {
+		if s.curFlags != flags {
+			panic("strobe: continued operation with different flags")
+		}
+		return
+	}
+	if flags&flagT != 0 {
+		panic("strobe: transport operations are not supported")
+	}
+	oldBegin := s.posBegin
+	s.posBegin = s.pos + 1
+	s.curFlags = flags
+	s.absorb([]byte{byte(oldBegin), flags})
+
+	// cipher and key operations start on a fresh block
+	if flags&(flagC|flagK) != 0 && s.pos != 0 {
+		s.runF()
+	}
+}
+
+var keccakRC = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808A, 0x8000000080008000,
+	0x000000000000808B, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008A, 0x0000000000000088, 0x0000000080008009, 0x000000008000000A,
+	0x000000008000808B, 0x800000000000008B, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800A, 0x800000008000000A,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+var keccakRotc = [24]int{1, 3, 6, 10, 15, 21, 28, 36, 45, 55, 2, 14, 27, 41, 56, 8, 25, 43, 62, 18, 39, 61, 20, 44}
+
+var keccakPiln = [24]int{10, 7, 11, 17, 18, 3, 5, 16, 8, 21, 24, 4, 15, 23, 19, 13, 12, 2, 20, 14, 22, 9, 6, 1}
+
+// keccakF1600 permute the state, lanes are little endian
+func keccakF1600(state *[200]byte) {
+	var a [25]uint64
+	for i := range a {
+		a[i] = binary.LittleEndian.Uint64(state[8*i:])
+	}
+	var c [5]uint64
+	for round := 0; round < 24; round++ {
+		// theta
+		for x := 0; x < 5; x++ {
+			c[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
+		}
+		for x := 0; x < 5; x++ {
+			d := c[(x+4)%5] ^ bits.RotateLeft64(c[(x+1)%5], 1)
+			for y := 0; y < 25; y += 5 {
+				a[y+x] ^= d
+			}
+		}
+		// rho and pi
+		t := a[1]
+		for i := 0; i < 24; i++ {
+			j := keccakPiln[i]
+			t, a[j] = a[j], bits.RotateLeft64(t, keccakRotc[i])
+		}
+		// chi
+		for y := 0; y < 25; y += 5 {
+			for x := 0; x < 5; x++ {
+				c[x] = a[y+x]
+			}
+			for x := 0; x < 5; x++ {
+				a[y+x] = c[x] ^ (^c[(x+1)%5] & c[(x+2)%5])
+			}
+		}
+		// iota
+		a[0] ^= keccakRC[round]
+	}
+	for i := range a {
+		binary.LittleEndian.PutUint64(state[8*i:], a[i])
+	}
+}
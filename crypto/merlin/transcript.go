@@ -0,0 +1,68 @@
+package merlin
+
+import (
+	"encoding/binary"
+)
+
+// Transcript merlin transcript (merlin.cool), a STROBE-128 based Fiat-Shamir transcript used by schnorrkel
+type Transcript struct {
+	strobe *strobe128
+}
+
+// NewTranscript transcript with the merlin protocol label and the application domain separator label
+func NewTranscript(label string) *Transcript {
+	t := &Transcript{strobe: newStrobe128([]byte("Merlin v1.0"))}
+	t.AppendMessage([]byte("dom-sep"), []byte(label))
+	return t
+}
+
+// Clone copy of the transcript, both can be continued independently
+func (t *Transcript) Clone() *Transcript {
+	return &Transcript{strobe: t.strobe.clone()}
+}
+
+// AppendMessage absorb a labeled message
+func (t *Transcript) AppendMessage(label, message []byte) {
+	t.strobe.metaAD(label, false)
+	t.strobe.metaAD(le32(len(message)), true)
+	t.strobe.ad(message, false)
+}
+
+// AppendUint64 absorb a labeled little endian u64
+func (t *Transcript) AppendUint64(label []byte, x uint64) {
+	var data [8]byte
+	binary.LittleEndian.PutUint64(data[:], x)
+	t.AppendMessage(label, data[:])
+}
+
+// ChallengeBytes squeeze n labeled challenge bytes
+func (t *Transcript) ChallengeBytes(label []byte, n int) []byte {
+	dest := make([]byte, n)
+	t.strobe.metaAD(label, false)
+	t.strobe.metaAD(le32(n), true)
+	t.strobe.prf(dest, false)
+	return dest
+}
+
+// WitnessBytes n bytes derived from the transcript, the secret witnesses and rand, the transcript itself is not changed
+// follows merlin's TranscriptRngBuilder, rand must be a fresh random value
+func (t *Transcript) WitnessBytes(label []byte, n int, witnesses [][]byte, rand []byte) []byte {
+	strobe := t.strobe.clone()
+	for _, w := range witnesses {
+		strobe.metaAD(label, false)
+		strobe.key(w, false)
+	}
+	strobe.metaAD([]byte("rng"), false)
+	strobe.key(rand, false)
+
+	dest := make([]byte, n)
+	strobe.metaAD(le32(n), false)
+	strobe.prf(dest, false)
+	return dest
+}
+
+func le32(n int) []byte {
+	var data [4]byte
+	binary.LittleEndian.PutUint32(data[:], uint32(n))
+	return data[:]
+}
@@ -0,0 +1,76 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+)
+
+const hedgedNonceLabel = "threshold-lib hedged nonce"
+
+// usedNonceIds nonce ids claimed in this process, sha256(label || secret || nonceId) -> struct{}
+var usedNonceIds sync.Map
+
+// ClaimNonceId reserve nonceId for hedged nonces of secret, an id that was already claimed returns an error
+// a failed or retried signing session must use a new id: with a stuck rand the same id gives the same nonce
+// while a peer may change its own nonce, two challenges for one nonce reveal the share
+// ids are only remembered by this process, after a restart the caller must keep them unique, eg: a persisted counter
+func ClaimNonceId(secret *big.Int, label string, nonceId []byte) error {
+	if secret == nil || len(nonceId) == 0 {
+		return fmt.Errorf("ClaimNonceId parameters error")
+	}
+	hash := sha256.New()
+	for _, in := range [][]byte{[]byte(label), secret.Bytes(), nonceId} {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(in)))
+		hash.Write(length[:])
+		hash.Write(in)
+	}
+	key := hex.EncodeToString(hash.Sum(nil))
+	if _, loaded := usedNonceIds.LoadOrStore(key, struct{}{}); loaded {
+		return fmt.Errorf("nonce id already used, every signing session needs a new id")
+	}
+	return nil
+}
+
+// HedgedNonce RFC6979-style nonce hedged with fresh randomness, 0 < k < n
+// k = HMAC-SHA512(key = secret, label || rand || inputs || counter) mod n, inputs are length prefixed
+// a stuck rand still gives distinct nonces as long as the inputs differ, a good rand keeps k unpredictable
+func HedgedNonce(secret, n *big.Int, rand io.Reader, inputs ...[]byte) (*big.Int, error) {
+	if secret == nil || n == nil || n.Cmp(one) != 1 || rand == nil {
+		return nil, fmt.Errorf("HedgedNonce parameters error")
+	}
+	fresh := make([]byte, 32)
+	_, err := io.ReadFull(rand, fresh)
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, (n.BitLen()+7)/8)
+	secret = new(big.Int).Mod(secret, n)
+	secret.FillBytes(key)
+
+	var length [8]byte
+	for counter := uint32(0); ; counter++ {
+		mac := hmac.New(sha512.New, key)
+		mac.Write([]byte(hedgedNonceLabel))
+		mac.Write(fresh)
+		for _, in := range inputs {
+			binary.BigEndian.PutUint64(length[:], uint64(len(in)))
+			mac.Write(length[:])
+			mac.Write(in)
+		}
+		var ctr [4]byte
+		binary.BigEndian.PutUint32(ctr[:], counter)
+		mac.Write(ctr[:])
+		k := new(big.Int).Mod(new(big.Int).SetBytes(mac.Sum(nil)), n)
+		if k.Sign() > 0 {
+			return k, nil
+		}
+	}
+}
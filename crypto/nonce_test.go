@@ -0,0 +1,46 @@
+package crypto
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestHedgedNonceStuckRand(t *testing.T) {
+	secret := RandomNum(big.NewInt(1 << 62))
+	n := new(big.Int).Lsh(big.NewInt(1), 255)
+	stuck := func() *bytes.Reader { return bytes.NewReader(make([]byte, 32)) }
+	nonce := func(session, message, id string) *big.Int {
+		k, err := HedgedNonce(secret, n, stuck(), []byte(session), []byte(message), []byte(id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	// a failing rand repeats the nonce only for an identical session
+	k := nonce("session", "message", "id-1")
+	if nonce("session", "message", "id-1").Cmp(k) != 0 {
+		t.Fatal("identical inputs should give the same nonce with a stuck rand")
+	}
+	for _, other := range []*big.Int{nonce("session", "message", "id-2"), nonce("session", "other", "id-1"), nonce("other", "message", "id-1")} {
+		if other.Cmp(k) == 0 {
+			t.Fatal("a different session should give a different nonce")
+		}
+	}
+}
+
+func TestClaimNonceId(t *testing.T) {
+	secret := RandomNum(big.NewInt(1 << 62))
+	if err := ClaimNonceId(secret, "test", []byte("id")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ClaimNonceId(secret, "test", []byte("id")); err == nil {
+		t.Fatal("reused nonce id should be refused")
+	}
+	if err := ClaimNonceId(secret, "test", []byte("id-2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ClaimNonceId(new(big.Int).Add(secret, big.NewInt(1)), "test", []byte("id")); err != nil {
+		t.Fatal("the same id of another share is allowed", err)
+	}
+}
@@ -1,6 +1,7 @@
 package paillier
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"runtime"
@@ -9,6 +10,7 @@ import (
 )
 
 const (
+	// PrimeBits modulus size of the default profile
 	PrimeBits = 2048
 )
 
@@ -21,12 +23,15 @@ type (
 		PublicKey
 		Lambda *big.Int // lcm(p-1, q-1)
 		Phi    *big.Int // (p-1) * (q-1)
+		P, Q   *big.Int // n = p*q, nil for keys serialized before, crt is skipped
 	}
 )
 
 var (
 	zero = big.NewInt(0)
 	one  = big.NewInt(1)
+	// product of odd primes below 1000
+	smallPrimesProduct = oddPrimesProduct(1000)
 )
 
 // NewKeyPair generate paillier key pair
@@ -37,17 +42,25 @@ func NewKeyPair(concurrency ...int) (*PrivateKey, *PublicKey, error) {
 	} else {
 		currency = runtime.NumCPU()
 	}
+	return NewKeyPairWithContext(context.Background(), currency)
+}
 
-	var values = make(chan *big.Int, currency)
-	var p, q *big.Int
-	for p == q {
-		var quit = make(chan int)
-		for i := 0; i < currency; i++ {
-			go crypto.GenerateSafePrime(PrimeBits/2, values, quit)
-		}
-		p, q = <-values, <-values
-		close(quit)
+// NewKeyPairWithContext generate paillier key pair, stops when ctx is done
+func NewKeyPairWithContext(ctx context.Context, concurrency int) (*PrivateKey, *PublicKey, error) {
+	return NewKeyPairWithProfile(ctx, DefaultProfile, concurrency)
+}
+
+// NewKeyPairWithProfile generate paillier key pair of profile.PaillierBits
+func NewKeyPairWithProfile(ctx context.Context, profile *SecurityProfile, concurrency int) (*PrivateKey, *PublicKey, error) {
+	err := profile.Check()
+	if err != nil {
+		return nil, nil, err
+	}
+	primes, err := crypto.GenerateSafePrimes(ctx, profile.PaillierBits/2, 2, &crypto.SafePrimeOptions{Concurrency: concurrency})
+	if err != nil {
+		return nil, nil, err
 	}
+	p, q := primes[0], primes[1]
 
 	// n = p*q
 	n := new(big.Int).Mul(p, q)
@@ -62,7 +75,7 @@ func NewKeyPair(concurrency ...int) (*PrivateKey, *PublicKey, error) {
 	lambda := new(big.Int).Div(phi, gcd)
 
 	publicKey := &PublicKey{N: n}
-	privateKey := &PrivateKey{PublicKey: *publicKey, Lambda: lambda, Phi: phi}
+	privateKey := &PrivateKey{PublicKey: *publicKey, Lambda: lambda, Phi: phi, P: p, Q: q}
 	return privateKey, publicKey, nil
 }
 
@@ -99,23 +112,26 @@ func (pk *PublicKey) HomoMulPlain(c1, m *big.Int) (*big.Int, error) {
 	if m.Cmp(zero) == -1 || m.Cmp(pk.N) != -1 { // 0 <=  m < N
 		return nil, fmt.Errorf("m range error")
 	}
-	N2 := pk.N2()
-	if c1.Cmp(zero) == -1 || c1.Cmp(N2) != -1 { //  // 0 <= c1 < N2
-		return nil, fmt.Errorf("c range error")
+	err := pk.ValidateCiphertext(c1)
+	if err != nil {
+		return nil, err
 	}
+	N2 := pk.N2()
 	// c^m mod N2
 	return new(big.Int).Exp(c1, m, N2), nil
 }
 
 // HomoAdd E(ab)=E(a)*E(b) mod n^2
 func (pk *PublicKey) HomoAdd(c1, c2 *big.Int) (*big.Int, error) {
-	N2 := pk.N2()
-	if c1.Cmp(zero) == -1 || c1.Cmp(N2) != -1 { //  // 0 <= c1 < N2
-		return nil, fmt.Errorf("c1 range error")
+	err := pk.ValidateCiphertext(c1)
+	if err != nil {
+		return nil, fmt.Errorf("c1 %v", err)
 	}
-	if c2.Cmp(zero) == -1 || c2.Cmp(N2) != -1 { //  // 0 <= c2 < N2
-		return nil, fmt.Errorf("c2 range error")
+	err = pk.ValidateCiphertext(c2)
+	if err != nil {
+		return nil, fmt.Errorf("c2 %v", err)
 	}
+	N2 := pk.N2()
 	// c1 * c2 mod N2
 	return new(big.Int).Mod(new(big.Int).Mul(c1, c2), N2), nil
 }
@@ -123,17 +139,56 @@ func (pk *PublicKey) HomoAdd(c1, c2 *big.Int) (*big.Int, error) {
 // HomoAddPlain   E(a+b) = E(a) * g^b mod n^2
 //						 = E(a) * (1 + b*n) mod n^2
 func (pk *PublicKey) HomoAddPlain(eA, b *big.Int) (*big.Int, error) {
-	N2 := pk.N2()
-	if eA.Cmp(zero) == -1 || eA.Cmp(N2) != -1 { //  // 0 <= eA < N2
-		return nil, fmt.Errorf("eA range error")
+	err := pk.ValidateCiphertext(eA)
+	if err != nil {
+		return nil, fmt.Errorf("eA %v", err)
 	}
 	if b.Cmp(zero) == -1 || b.Cmp(pk.N) != -1 { //  // 0 <= b < N
-		return nil, fmt.Errorf("c2 range error")
+		return nil, fmt.Errorf("b range error")
 	}
+	N2 := pk.N2()
 	gb := new(big.Int).Add(new(big.Int).Mul(b, pk.N), one)
 	return new(big.Int).Mod(new(big.Int).Mul(eA, gb), N2), nil
 }
 
+// Validate public key received from another party, same as ValidateWithProfile(DefaultProfile)
+func (pk *PublicKey) Validate() error {
+	return pk.ValidateWithProfile(DefaultProfile)
+}
+
+// ValidateWithProfile N = p*q has at least the profile size, is odd and has no small factors
+func (pk *PublicKey) ValidateWithProfile(profile *SecurityProfile) error {
+	if pk == nil || pk.N == nil || pk.N.Sign() <= 0 {
+		return fmt.Errorf("invalid paillier public key")
+	}
+	err := profile.CheckPaillierModulus(pk.N)
+	if err != nil {
+		return fmt.Errorf("invalid paillier public key, %v", err)
+	}
+	if pk.N.Bit(0) == 0 {
+		return fmt.Errorf("invalid paillier public key, N is even")
+	}
+	if new(big.Int).GCD(nil, nil, pk.N, smallPrimesProduct).Cmp(one) != 0 {
+		return fmt.Errorf("invalid paillier public key, N has small factors")
+	}
+	sqrt := new(big.Int).Sqrt(pk.N)
+	if new(big.Int).Mul(sqrt, sqrt).Cmp(pk.N) == 0 {
+		return fmt.Errorf("invalid paillier public key, N is a square")
+	}
+	return nil
+}
+
+// ValidateCiphertext c in Z*_{N^2}, 0 < c < N^2 and gcd(c, N) = 1
+func (pk *PublicKey) ValidateCiphertext(c *big.Int) error {
+	if c == nil || c.Sign() <= 0 || c.Cmp(pk.N2()) != -1 {
+		return fmt.Errorf("ciphertext range error")
+	}
+	if new(big.Int).GCD(nil, nil, c, pk.N).Cmp(one) != 0 {
+		return fmt.Errorf("ciphertext is not coprime to N")
+	}
+	return nil
+}
+
 // n*n
 func (pk *PublicKey) N2() *big.Int {
 	return new(big.Int).Mul(pk.N, pk.N)
@@ -146,13 +201,13 @@ func (pk *PublicKey) G() *big.Int {
 
 // Decrypt m = L(c^lambda mod n^2) * mu mod n
 func (priv *PrivateKey) Decrypt(c *big.Int) (m *big.Int, err error) {
-	N2 := priv.N2()
-	if c.Cmp(zero) == -1 || c.Cmp(N2) != -1 { // 0 <= c < N2
-		return nil, fmt.Errorf("c range error")
+	err = priv.ValidateCiphertext(c)
+	if err != nil {
+		return nil, err
 	}
-	cg := new(big.Int).GCD(nil, nil, c, N2)
-	if cg.Cmp(one) == 1 {
-		return nil, fmt.Errorf("the message is mal-formed")
+	N2 := priv.N2()
+	if priv.hasFactors() {
+		return priv.decryptCRT(c), nil
 	}
 	//  lc = L[(c^Lambda mod N2) / N]
 	lc := l(new(big.Int).Exp(c, priv.Lambda, N2), priv.N)
@@ -164,8 +219,80 @@ func (priv *PrivateKey) Decrypt(c *big.Int) (m *big.Int, err error) {
 	return
 }
 
+// decryptCRT mp = L_p(c^(p-1) mod p^2) * hp mod p, hp = L_p(g^(p-1) mod p^2)^-1 = (-q)^-1 mod p, same for q
+func (priv *PrivateKey) decryptCRT(c *big.Int) *big.Int {
+	mp := decryptModPrime(c, priv.P, priv.Q)
+	mq := decryptModPrime(c, priv.Q, priv.P)
+	return crt(mp, mq, priv.P, priv.Q)
+}
+
+func decryptModPrime(c, p, q *big.Int) *big.Int {
+	p2 := new(big.Int).Mul(p, p)
+	pMinus1 := new(big.Int).Sub(p, one)
+	lp := l(new(big.Int).Exp(new(big.Int).Mod(c, p2), pMinus1, p2), p)
+	hp := new(big.Int).ModInverse(new(big.Int).Sub(p, new(big.Int).Mod(q, p)), p)
+	return new(big.Int).Mod(new(big.Int).Mul(lp, hp), p)
+}
+
+// Encrypt key holder encryption, r^n is computed by crt
+func (priv *PrivateKey) Encrypt(m *big.Int) (*big.Int, *big.Int, error) {
+	r, err := crypto.RandomPrimeNum(priv.N)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getRandom error")
+	}
+	c, err := priv.EncryptWithR(m, r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("EncryptRandom error")
+	}
+	return c, r, err
+}
+
+// EncryptWithR E(m) = (1 + m*n) * (r^n) mod n^2, r^n mod p^2 and q^2 with exponents reduced by p(p-1) and q(q-1)
+func (priv *PrivateKey) EncryptWithR(m, r *big.Int) (*big.Int, error) {
+	if !priv.hasFactors() {
+		return priv.PublicKey.EncryptWithR(m, r)
+	}
+	if m.Cmp(zero) == -1 || m.Cmp(priv.N) != -1 { // 0 <=  m < N
+		return nil, fmt.Errorf("m range error")
+	}
+	N2 := priv.N2()
+	p2 := new(big.Int).Mul(priv.P, priv.P)
+	q2 := new(big.Int).Mul(priv.Q, priv.Q)
+	xp := new(big.Int).Exp(r, new(big.Int).Mod(priv.N, new(big.Int).Mul(priv.P, new(big.Int).Sub(priv.P, one))), p2)
+	xq := new(big.Int).Exp(r, new(big.Int).Mod(priv.N, new(big.Int).Mul(priv.Q, new(big.Int).Sub(priv.Q, one))), q2)
+	xN := crt(xp, xq, p2, q2)
+	// g^m = 1 + m*n mod N2
+	Gm := new(big.Int).Add(new(big.Int).Mul(m, priv.N), one)
+	return new(big.Int).Mod(new(big.Int).Mul(Gm, xN), N2), nil
+}
+
+func (priv *PrivateKey) hasFactors() bool {
+	return priv.P != nil && priv.Q != nil && new(big.Int).Mul(priv.P, priv.Q).Cmp(priv.N) == 0
+}
+
+// crt x = a mod m1, x = b mod m2, gcd(m1, m2) = 1
+func crt(a, b, m1, m2 *big.Int) *big.Int {
+	inv := new(big.Int).ModInverse(m1, m2)
+	// x = a + m1 * ((b - a) * m1^-1 mod m2)
+	h := new(big.Int).Sub(b, a)
+	h.Mul(h, inv)
+	h.Mod(h, m2)
+	return h.Mul(h, m1).Add(h, a)
+}
+
 // l(x) = (x-1)/N
 func l(u, N *big.Int) *big.Int {
 	t := new(big.Int).Sub(u, one)
 	return new(big.Int).Div(t, N)
 }
+
+func oddPrimesProduct(limit int64) *big.Int {
+	product := big.NewInt(1)
+	for i := int64(3); i < limit; i += 2 {
+		n := big.NewInt(i)
+		if n.ProbablyPrime(0) {
+			product.Mul(product, n)
+		}
+	}
+	return product
+}
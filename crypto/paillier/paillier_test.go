@@ -1,9 +1,12 @@
 package paillier
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"testing"
+
+	"github.com/okx/threshold-lib/crypto"
 )
 
 func TestPaillier(t *testing.T) {
@@ -29,3 +32,118 @@ func TestNIZK(t *testing.T) {
 	verify := NIZKVerify(publicKey.N, proof)
 	fmt.Println(verify)
 }
+
+func TestPaillierCRT(t *testing.T) {
+	privateKey, publicKey, _ := NewKeyPair(8)
+	legacyKey := &PrivateKey{PublicKey: privateKey.PublicKey, Lambda: privateKey.Lambda, Phi: privateKey.Phi}
+
+	m := crypto.RandomNum(publicKey.N)
+	c1, r, _ := publicKey.Encrypt(m)
+	c2, _ := privateKey.EncryptWithR(m, r)
+	if c1.Cmp(c2) != 0 {
+		t.Fatal("crt encryption mismatch")
+	}
+	c3, _, _ := privateKey.Encrypt(m)
+	for _, c := range []*big.Int{c1, c3} {
+		m1, _ := privateKey.Decrypt(c)
+		m2, _ := legacyKey.Decrypt(c)
+		if m1.Cmp(m) != 0 || m2.Cmp(m) != 0 {
+			t.Fatal("decrypt error")
+		}
+	}
+
+	// P and Q are serialized
+	bytes, _ := json.Marshal(privateKey)
+	restored := &PrivateKey{}
+	_ = json.Unmarshal(bytes, restored)
+	if !restored.hasFactors() {
+		t.Fatal("factors not serialized")
+	}
+}
+
+func benchmarkKey(b *testing.B) *PrivateKey {
+	if benchKey == nil {
+		benchKey, _, _ = NewKeyPair()
+	}
+	b.ResetTimer()
+	return benchKey
+}
+
+var benchKey *PrivateKey
+
+func BenchmarkDecrypt(b *testing.B) {
+	privateKey := benchmarkKey(b)
+	c, _, _ := privateKey.Encrypt(big.NewInt(42))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		privateKey.Decrypt(c)
+	}
+}
+
+func BenchmarkDecryptWithoutCRT(b *testing.B) {
+	privateKey := benchmarkKey(b)
+	legacyKey := &PrivateKey{PublicKey: privateKey.PublicKey, Lambda: privateKey.Lambda, Phi: privateKey.Phi}
+	c, _, _ := privateKey.Encrypt(big.NewInt(42))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		legacyKey.Decrypt(c)
+	}
+}
+
+func BenchmarkEncrypt(b *testing.B) {
+	privateKey := benchmarkKey(b)
+	for i := 0; i < b.N; i++ {
+		privateKey.Encrypt(big.NewInt(42))
+	}
+}
+
+func BenchmarkEncryptWithoutCRT(b *testing.B) {
+	publicKey := &benchmarkKey(b).PublicKey
+	for i := 0; i < b.N; i++ {
+		publicKey.Encrypt(big.NewInt(42))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	privateKey, publicKey, _ := NewKeyPair(8)
+	if err := publicKey.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	badKeys := []*PublicKey{
+		{N: big.NewInt(0)},
+		{N: new(big.Int).Lsh(big.NewInt(1), PrimeBits-1)},                       // even
+		{N: new(big.Int).Mul(big.NewInt(3), new(big.Int).Rsh(privateKey.N, 2))}, // small factor
+		{N: new(big.Int).Mul(privateKey.P, privateKey.P)},                       // square
+	}
+	for i, pk := range badKeys {
+		if pk.Validate() == nil {
+			t.Fatal("invalid public key accepted", i)
+		}
+	}
+
+	c, _, _ := publicKey.Encrypt(big.NewInt(10))
+	N2 := publicKey.N2()
+	badCiphertexts := []*big.Int{
+		big.NewInt(0),
+		N2,
+		new(big.Int).Neg(c),
+		new(big.Int).Mul(privateKey.P, big.NewInt(7)), // not coprime to N
+	}
+	for i, bad := range badCiphertexts {
+		if publicKey.ValidateCiphertext(bad) == nil {
+			t.Fatal("invalid ciphertext accepted", i)
+		}
+		if _, err := publicKey.HomoAdd(c, bad); err == nil {
+			t.Fatal("HomoAdd accepted invalid ciphertext", i)
+		}
+		if _, err := publicKey.HomoAddPlain(bad, big.NewInt(1)); err == nil {
+			t.Fatal("HomoAddPlain accepted invalid ciphertext", i)
+		}
+		if _, err := publicKey.HomoMulPlain(bad, big.NewInt(2)); err == nil {
+			t.Fatal("HomoMulPlain accepted invalid ciphertext", i)
+		}
+		if _, err := privateKey.Decrypt(bad); err == nil {
+			t.Fatal("Decrypt accepted invalid ciphertext", i)
+		}
+	}
+}
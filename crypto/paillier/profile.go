@@ -0,0 +1,65 @@
+package paillier
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const (
+	minModulusBits = 2048
+	maxModulusBits = 8192
+)
+
+// SecurityProfile bit lengths of the paillier modulus N and the ring-pedersen modulus NTilde
+// the profile is a minimum, both parties refuse moduli below the agreed sizes
+type SecurityProfile struct {
+	PaillierBits     int
+	RingPedersenBits int
+}
+
+var (
+	Profile2048 = &SecurityProfile{PaillierBits: 2048, RingPedersenBits: 2048}
+	// Profile3072 long-lived custody keys, 128-bit security
+	Profile3072 = &SecurityProfile{PaillierBits: 3072, RingPedersenBits: 3072}
+
+	DefaultProfile = Profile2048
+)
+
+// Check profile sizes are supported, moduli are the product of two safe primes of half the size
+func (sp *SecurityProfile) Check() error {
+	if sp == nil {
+		return fmt.Errorf("security profile is nil")
+	}
+	for _, bits := range []int{sp.PaillierBits, sp.RingPedersenBits} {
+		if bits < minModulusBits || bits > maxModulusBits || bits%64 != 0 {
+			return fmt.Errorf("unsupported modulus size %d", bits)
+		}
+	}
+	return nil
+}
+
+// AtLeast both sizes are no smaller than min
+func (sp *SecurityProfile) AtLeast(min *SecurityProfile) bool {
+	return sp.PaillierBits >= min.PaillierBits && sp.RingPedersenBits >= min.RingPedersenBits
+}
+
+// CheckPaillierModulus N has at least the profile size, top bit may be lost in the product of two primes
+func (sp *SecurityProfile) CheckPaillierModulus(N *big.Int) error {
+	return checkModulusSize(N, sp.PaillierBits)
+}
+
+// CheckRingPedersenModulus NTilde has at least the profile size
+func (sp *SecurityProfile) CheckRingPedersenModulus(NTilde *big.Int) error {
+	return checkModulusSize(NTilde, sp.RingPedersenBits)
+}
+
+func checkModulusSize(N *big.Int, bits int) error {
+	if N == nil || N.Sign() <= 0 {
+		return fmt.Errorf("invalid modulus")
+	}
+	bitlen := N.BitLen()
+	if bitlen < bits-1 || bitlen > maxModulusBits {
+		return fmt.Errorf("modulus size %d out of range, minimum %d", bitlen, bits)
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package crypto
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// sieve primes below sieveLimit, excluding 2
+	sieveLimit = 1 << 15
+	// candidates q0, q0+2, ... before a new random start
+	sieveRange = 1 << 20
+	// each worker reports its sieved candidates every progressInterval, and when it leaves a range
+	progressInterval = 1 << 11
+)
+
+var smallPrimes = sievePrimes(sieveLimit)
+
+// SafePrimeOptions options of safe prime generation, nil uses defaults
+type SafePrimeOptions struct {
+	Concurrency int // number of workers, default runtime.NumCPU()
+	// Seed deterministic mode for tests, a single worker draws from a stream expanded from the seed, never use in production
+	Seed []byte
+	// Progress called with the total number of candidates q sieved so far, must be safe for concurrent use
+	Progress func(candidates uint64)
+}
+
+// GenerateSafePrimeWithContext generates a safe prime p = 2q+1 of bits length, q is also prime
+func GenerateSafePrimeWithContext(ctx context.Context, bits int, opts *SafePrimeOptions) (*big.Int, error) {
+	primes, err := GenerateSafePrimes(ctx, bits, 1, opts)
+	if err != nil {
+		return nil, err
+	}
+	return primes[0], nil
+}
+
+// GenerateSafePrimes generates n distinct safe primes of bits length, workers search in parallel
+// both q and 2q+1 are sieved by small primes before the probabilistic primality tests
+func GenerateSafePrimes(ctx context.Context, bits, n int, opts *SafePrimeOptions) ([]*big.Int, error) {
+	if bits < 32 || n <= 0 {
+		return nil, fmt.Errorf("GenerateSafePrimes params error")
+	}
+	if opts == nil {
+		opts = &SafePrimeOptions{}
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	var reader io.Reader = rand.Reader
+	if opts.Seed != nil {
+		reader = NewSeededReader(opts.Seed)
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		candidates uint64
+		wg         sync.WaitGroup
+		errOnce    sync.Once
+		workerErr  error
+	)
+	values := make(chan *big.Int, concurrency)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := safePrimeWorker(ctx, bits, reader, values, &candidates, opts.Progress)
+			if err != nil && ctx.Err() == nil {
+				errOnce.Do(func() { workerErr = err })
+				cancel()
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(values)
+	}()
+
+	primes := make([]*big.Int, 0, n)
+	for p := range values {
+		if containsInt(primes, p) {
+			continue
+		}
+		primes = append(primes, p)
+		if len(primes) == n {
+			cancel()
+			break
+		}
+	}
+	// drain, the workers stop on cancel
+	for range values {
+	}
+	if len(primes) == n {
+		return primes, nil
+	}
+	if workerErr != nil {
+		return nil, workerErr
+	}
+	return nil, ctx.Err()
+}
+
+// safePrimeWorker search from random starting points until ctx is done
+func safePrimeWorker(ctx context.Context, bits int, reader io.Reader, values chan<- *big.Int, candidates *uint64, progress func(uint64)) error {
+	qBits := bits - 1
+	buf := make([]byte, (qBits+7)/8)
+	residues := make([]uint64, len(smallPrimes))
+	q := new(big.Int)
+	p := new(big.Int)
+	var pending uint64
+	flush := func() {
+		if pending == 0 {
+			return
+		}
+		total := atomic.AddUint64(candidates, pending)
+		pending = 0
+		if progress != nil {
+			progress(total)
+		}
+	}
+	defer flush()
+	for {
+		flush()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q0, err := randomStart(reader, buf, qBits)
+		if err != nil {
+			return err
+		}
+		for i, prime := range smallPrimes {
+			residues[i] = new(big.Int).Mod(q0, new(big.Int).SetUint64(prime)).Uint64()
+		}
+		for delta := uint64(0); delta < sieveRange; delta += 2 {
+			if pending == progressInterval {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				flush()
+			}
+			pending++
+			if !sieve(residues, delta) {
+				continue
+			}
+			q.Add(q0, new(big.Int).SetUint64(delta))
+			if q.BitLen() != qBits {
+				break
+			}
+			// p = 2q+1
+			p.Lsh(q, 1)
+			p.Add(p, one)
+			// cheap Baillie-PSW first, p is checked before q since it fails more often
+			if !p.ProbablyPrime(0) || !q.ProbablyPrime(0) {
+				continue
+			}
+			if !q.ProbablyPrime(20) || !p.ProbablyPrime(20) {
+				continue
+			}
+			select {
+			case values <- new(big.Int).Set(p):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			break
+		}
+	}
+}
+
+// sieve q = q0+delta and 2q+1 have no small prime factor
+func sieve(residues []uint64, delta uint64) bool {
+	for i, prime := range smallPrimes {
+		r := (residues[i] + delta) % prime
+		if r == 0 || (2*r+1)%prime == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// randomStart odd number of bits length with the top two bits set, so that the product of two safe primes has 2*bits bits
+func randomStart(reader io.Reader, buf []byte, bits int) (*big.Int, error) {
+	_, err := io.ReadFull(reader, buf)
+	if err != nil {
+		return nil, err
+	}
+	// clear bits above the length
+	excess := uint(len(buf)*8 - bits)
+	buf[0] &= byte(0xff >> excess)
+	q0 := new(big.Int).SetBytes(buf)
+	q0.SetBit(q0, bits-1, 1)
+	q0.SetBit(q0, bits-2, 1)
+	q0.SetBit(q0, 0, 1)
+	return q0, nil
+}
+
+func sievePrimes(limit int) []uint64 {
+	composite := make([]bool, limit)
+	var primes []uint64
+	for i := 3; i < limit; i += 2 {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, uint64(i))
+		for j := i * i; j < limit; j += 2 * i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
+
+func containsInt(list []*big.Int, n *big.Int) bool {
+	for _, v := range list {
+		if v.Cmp(n) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// seededReader sha256(seed || counter) stream, safe for concurrent use
+type seededReader struct {
+	mu      sync.Mutex
+	seed    [32]byte
+	counter uint64
+	buf     []byte
+}
+
+// NewSeededReader deterministic random stream for tests, never use in production
+func NewSeededReader(seed []byte) io.Reader {
+	return &seededReader{seed: sha256.Sum256(seed)}
+}
+
+func (r *seededReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for n < len(p) {
+		if len(r.buf) == 0 {
+			var block [40]byte
+			copy(block[:], r.seed[:])
+			binary.BigEndian.PutUint64(block[32:], r.counter)
+			r.counter++
+			sum := sha256.Sum256(block[:])
+			r.buf = sum[:]
+		}
+		c := copy(p[n:], r.buf)
+		r.buf = r.buf[c:]
+		n += c
+	}
+	return n, nil
+}
@@ -0,0 +1,120 @@
+package crypto
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSafePrime(t *testing.T) {
+	ctx := context.Background()
+	var progress, decreased uint64
+	opts := &SafePrimeOptions{
+		Seed: []byte("safe prime test"),
+		Progress: func(candidates uint64) {
+			if atomic.SwapUint64(&progress, candidates) > candidates {
+				atomic.StoreUint64(&decreased, 1)
+			}
+		},
+	}
+	primes1, err := GenerateSafePrimes(ctx, 256, 2, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadUint64(&progress) == 0 || atomic.LoadUint64(&decreased) != 0 {
+		t.Fatal("progress should be reported and increase", progress)
+	}
+	atomic.StoreUint64(&progress, 0)
+	primes2, err := GenerateSafePrimes(ctx, 256, 2, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, p := range primes1 {
+		if p.Cmp(primes2[i]) != 0 {
+			t.Fatal("seeded generation is not deterministic")
+		}
+		q := new(big.Int).Rsh(p, 1)
+		if p.BitLen() != 256 || !p.ProbablyPrime(20) || !q.ProbablyPrime(20) {
+			t.Fatal("not a safe prime", p)
+		}
+	}
+	if primes1[0].Cmp(primes1[1]) == 0 {
+		t.Fatal("safe primes must be distinct")
+	}
+	p, err := GenerateSafePrimeWithContext(ctx, 512, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.BitLen() != 512 || !new(big.Int).Rsh(p, 1).ProbablyPrime(20) {
+		t.Fatal("not a safe prime", p)
+	}
+}
+
+func TestSafePrimeDeprecated(t *testing.T) {
+	values := make(chan *big.Int, 1)
+	p, err := GenerateSafePrime(256, values, make(chan int))
+	if err != nil || p == nil || p.Cmp(<-values) != 0 {
+		t.Fatal("safe prime should be returned and sent", err)
+	}
+	quit := make(chan int)
+	close(quit)
+	p, err = GenerateSafePrime(4096, values, quit)
+	if err != nil || p != nil {
+		t.Fatal("quit should stop the generation", err)
+	}
+}
+
+func TestSafePrimeCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := GenerateSafePrimes(ctx, 4096, 2, nil)
+	if err != context.DeadlineExceeded {
+		t.Fatal("expected deadline exceeded", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("cancellation too slow")
+	}
+}
+
+// naiveSafePrime previous generator, random prime q until 2q+1 is prime
+func naiveSafePrime(bits int) *big.Int {
+	for {
+		q, err := rand.Prime(rand.Reader, bits-1)
+		if err != nil {
+			panic(err)
+		}
+		p := new(big.Int).Add(new(big.Int).Lsh(q, 1), one)
+		if p.ProbablyPrime(20) {
+			return p
+		}
+	}
+}
+
+func benchmarkSafePrime(b *testing.B, bits int) {
+	for i := 0; i < b.N; i++ {
+		_, err := GenerateSafePrimeWithContext(context.Background(), bits, &SafePrimeOptions{Concurrency: 1})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSafePrime1024(b *testing.B) { benchmarkSafePrime(b, 1024) }
+func BenchmarkSafePrime1536(b *testing.B) { benchmarkSafePrime(b, 1536) }
+
+func BenchmarkNaiveSafePrime1024(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		naiveSafePrime(1024)
+	}
+}
+
+func BenchmarkNaiveSafePrime1536(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		naiveSafePrime(1536)
+	}
+}
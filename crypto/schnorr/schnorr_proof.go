@@ -1,6 +1,7 @@
 package schnorr
 
 import (
+	"crypto/elliptic"
 	"fmt"
 	"math/big"
 
@@ -23,8 +24,10 @@ func Prove(x *big.Int, X *curves.ECPoint) (*Proof, error) {
 	r := crypto.RandomNum(q)
 	R := curves.ScalarToPoint(X.Curve, r)
 
-	h := crypto.SHA256Int(X.X, X.Y, R.X, R.Y)
-	h = new(big.Int).Mod(h, q)
+	h, err := challenge(X.Curve, X.X, X.Y, R.X, R.Y)
+	if err != nil {
+		return nil, err
+	}
 
 	s := new(big.Int).Mul(h, x)
 	s = new(big.Int).Mod(new(big.Int).Add(r, s), q)
@@ -39,9 +42,10 @@ func Verify(pf *Proof, X *curves.ECPoint) bool {
 	if !pf.R.IsOnCurve() || !X.IsOnCurve() {
 		return false
 	}
-	q := X.Curve.Params().N
-	h := crypto.SHA256Int(X.X, X.Y, pf.R.X, pf.R.Y)
-	h = new(big.Int).Mod(h, q)
+	h, err := challenge(X.Curve, X.X, X.Y, pf.R.X, pf.R.Y)
+	if err != nil {
+		return false
+	}
 
 	SG := curves.ScalarToPoint(X.Curve, pf.S)
 	Xh := X.ScalarMult(h)
@@ -63,8 +67,10 @@ func ProveWithId(sessionId, x *big.Int, X *curves.ECPoint) (*Proof, error) {
 	R := curves.ScalarToPoint(X.Curve, r)
 	G := curves.ScalarToPoint(X.Curve, big.NewInt(1))
 
-	h := crypto.SHA256Int(sessionId, G.X, G.Y, X.X, X.Y, R.X, R.Y)
-	h = new(big.Int).Mod(h, q)
+	h, err := challenge(X.Curve, sessionId, G.X, G.Y, X.X, X.Y, R.X, R.Y)
+	if err != nil {
+		return nil, err
+	}
 
 	s := new(big.Int).Mul(h, x)
 	s = new(big.Int).Mod(new(big.Int).Add(r, s), q)
@@ -79,11 +85,12 @@ func VerifyWithId(sessionId *big.Int, pf *Proof, X *curves.ECPoint) bool {
 	if !pf.R.IsOnCurve() || !X.IsOnCurve() {
 		return false
 	}
-	q := X.Curve.Params().N
 	G := curves.ScalarToPoint(X.Curve, big.NewInt(1))
 
-	h := crypto.SHA256Int(sessionId, G.X, G.Y, X.X, X.Y, pf.R.X, pf.R.Y)
-	h = new(big.Int).Mod(h, q)
+	h, err := challenge(X.Curve, sessionId, G.X, G.Y, X.X, X.Y, pf.R.X, pf.R.Y)
+	if err != nil {
+		return false
+	}
 
 	SG := curves.ScalarToPoint(X.Curve, pf.S)
 	Xh := X.ScalarMult(h)
@@ -93,3 +100,20 @@ func VerifyWithId(sessionId *big.Int, pf *Proof, X *curves.ECPoint) bool {
 	}
 	return RXh.X.Cmp(SG.X) == 0 && RXh.Y.Cmp(SG.Y) == 0
 }
+
+// challenge SHA256(inputs) mod q on the builtin curves, unchanged so proofs stay compatible with deployed devices,
+// curves added by curves.Register use curves.HashToScalar of the length framed inputs
+func challenge(curve elliptic.Curve, in ...*big.Int) (*big.Int, error) {
+	data := make([][]byte, len(in))
+	for i, n := range in {
+		if n == nil {
+			return nil, fmt.Errorf("schnorr challenge input is nil")
+		}
+		data[i] = n.Bytes()
+	}
+	if curves.IsBuiltin(curve) {
+		h := crypto.SHA256Int(in...)
+		return h.Mod(h, curve.Params().N), nil
+	}
+	return curves.HashToScalar(curve, data...)
+}
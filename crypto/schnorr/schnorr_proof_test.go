@@ -38,4 +38,16 @@ func TestProofFaulty(t *testing.T) {
 	if res {
 		t.Fatal("result should be false")
 	}
-}
\ No newline at end of file
+}
+func TestProofLegacyChallenge(t *testing.T) {
+	// proof built with the challenge of deployed devices, SHA256(X, R) mod q
+	q := secp256k1.S256().N
+	x, r := crypto.RandomNum(q), crypto.RandomNum(q)
+	X := curves.ScalarToPoint(secp256k1.S256(), x)
+	R := curves.ScalarToPoint(secp256k1.S256(), r)
+	h := new(big.Int).Mod(crypto.SHA256Int(X.X, X.Y, R.X, R.Y), q)
+	s := new(big.Int).Mod(new(big.Int).Add(r, new(big.Int).Mul(h, x)), q)
+	if !Verify(&Proof{R: R, S: s}, X) {
+		t.Fatal("builtin curves must keep the legacy challenge")
+	}
+}
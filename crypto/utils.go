@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
@@ -71,31 +72,32 @@ func RandomPrimeNum(n *big.Int) (*big.Int, error) {
 	return r, nil
 }
 
-// GenerateSafePrime generates a prime number `p`; a prime 'p' such that 2p+1 is also prime.
+// GenerateSafePrime generates a safe prime p = 2q+1 of bits length, sends it to values and returns it
+// returns nil when quit is closed or receives first
+//
+// Deprecated: use GenerateSafePrimeWithContext
 func GenerateSafePrime(bits int, values chan *big.Int, quit chan int) (p *big.Int, err error) {
-	for {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
 		select {
 		case <-quit:
-			return
-		default:
-			// this is to make it non-blocking
+			cancel()
+		case <-ctx.Done():
 		}
-		p, err = rand.Prime(rand.Reader, bits-1)
-		if err != nil {
-			return nil, err
-		}
-		// 2p+1
-		p = new(big.Int).Lsh(p, 1)
-		p = new(big.Int).Add(p, one)
-		if p.ProbablyPrime(20) {
-			select {
-			case <-quit:
-				return
-			default:
-				// this is to make it non-blocking
-			}
-			values <- p
-			return
+	}()
+	p, err = GenerateSafePrimeWithContext(ctx, bits, &SafePrimeOptions{Concurrency: 1})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil
 		}
+		return nil, err
+	}
+	select {
+	case <-quit:
+		return nil, nil
+	default:
 	}
+	values <- p
+	return p, nil
 }
@@ -28,13 +28,28 @@ func NewFeldman(threshold, limit int, curve elliptic.Curve) (*Feldman, error) {
 
 // Evaluate return verifiers and shares
 func (fm *Feldman) Evaluate(secret *big.Int) ([]*curves.ECPoint, []*Share, error) {
+	ids := make([]int, fm.limit)
+	for i := 1; i <= fm.limit; i++ {
+		ids[i-1] = i
+	}
+	return fm.EvaluateWithIds(secret, ids)
+}
+
+// EvaluateWithIds return verifiers and shares evaluated at the given ids
+func (fm *Feldman) EvaluateWithIds(secret *big.Int, ids []int) ([]*curves.ECPoint, []*Share, error) {
+	if len(ids) != fm.limit {
+		return nil, nil, fmt.Errorf("feldman evaluate ids number error")
+	}
 	poly, err := InitPolynomial(fm.curve, secret, fm.threshold-1)
 	if err != nil {
 		return nil, nil, err
 	}
 	shares := make([]*Share, fm.limit)
-	for i := 1; i <= fm.limit; i++ {
-		shares[i-1] = poly.EvaluatePolynomial(big.NewInt(int64(i)))
+	for i, id := range ids {
+		if id <= 0 {
+			return nil, nil, fmt.Errorf("feldman evaluate id must be positive")
+		}
+		shares[i] = poly.EvaluatePolynomial(big.NewInt(int64(id)))
 	}
 	verifiers := make([]*curves.ECPoint, len(poly.Coefficients))
 	for i, c := range poly.Coefficients {
@@ -1,11 +1,13 @@
 package zkp
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v2"
 	"github.com/okx/threshold-lib/crypto"
 	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/paillier"
 )
 
 // partly ported from:
@@ -24,6 +26,7 @@ type (
 		N              *big.Int
 		Q, G           *curves.ECPoint
 		H1, H2, NTilde *big.Int
+		Profile        *paillier.SecurityProfile // minimum N and NTilde sizes, nil for paillier.DefaultProfile
 	}
 
 	StatementParams struct {
@@ -44,11 +47,19 @@ type (
 
 var (
 	one = big.NewInt(1)
-	q   = secp256k1.S256().N
+	// q default curve order of the range proofs, the PDL proof takes the order of the curve of G
+	q = secp256k1.S256().N
 )
 
 // NewPDLwSlackProof
 func NewPDLwSlackProve(wit *PDLwSlackWitness, st *PDLwSlackStatement) (*PDLwSlackProof, *StatementParams) {
+	if st.G == nil || st.Q == nil || checkStatement(st.Profile, st.N, st.NTilde, st.H1, st.H2) != nil {
+		return nil, nil
+	}
+	q := st.G.Curve.Params().N
+	if CheckOrder(q, st.N) != nil {
+		return nil, nil
+	}
 	q2 := new(big.Int).Mul(q, q)
 	q3 := new(big.Int).Mul(q2, q)
 	qNTilde := new(big.Int).Mul(q, st.NTilde)
@@ -92,11 +103,21 @@ func PDLwSlackVerify(pf *PDLwSlackProof, st *PDLwSlackStatement) bool {
 	if pf == nil || st == nil {
 		return false
 	}
+	if st.G == nil || st.Q == nil || checkStatement(st.Profile, st.N, st.NTilde, st.H1, st.H2) != nil {
+		return false
+	}
+	if pf.U1 == nil || pf.S1 == nil || pf.S2 == nil || pf.S3 == nil || pf.Z == nil || pf.U2 == nil || pf.U3 == nil {
+		return false
+	}
+	q := st.G.Curve.Params().N
+	if CheckOrder(q, st.N) != nil {
+		return false
+	}
 	e := crypto.SHA256Int(st.G.X, st.G.Y, st.Q.X, st.Q.Y, st.CipherText, pf.Z, pf.U1.X, pf.U1.Y, pf.U2, pf.U3)
 
-	// u1 = s1*G + (q-e)*Q
+	// u1 = s1*G + (q-e)*Q, e is a 256 bits hash and may exceed q
 	gS1 := st.G.ScalarMult(pf.S1)
-	eFeNeg := new(big.Int).Sub(q, e)
+	eFeNeg := new(big.Int).Mod(new(big.Int).Neg(e), q)
 	yMinusE := st.Q.ScalarMult(eFeNeg)
 	u1, err := gS1.Add(yMinusE)
 	if err != nil {
@@ -124,3 +145,39 @@ func commitmentUnknownOrder(h1, h2, NTilde, x, r *big.Int) (com *big.Int) {
 	com = new(big.Int).Mod(com, NTilde)
 	return
 }
+
+// CheckOrder N > q^5, the paillier plaintext space holds the slack range q^3 times the challenge and the 2-party signature
+// 2048 bits N covers curves up to 384 bits
+func CheckOrder(q, N *big.Int) error {
+	if q == nil || N == nil || q.Sign() <= 0 {
+		return fmt.Errorf("CheckOrder parameters error")
+	}
+	if N.BitLen() <= 5*q.BitLen() {
+		return fmt.Errorf("paillier modulus of %d bits too small for a %d bits curve order", N.BitLen(), q.BitLen())
+	}
+	return nil
+}
+
+// checkStatement N and NTilde sizes meet the profile, h1 and h2 are distinct non-trivial elements mod NTilde
+func checkStatement(profile *paillier.SecurityProfile, N, NTilde, h1, h2 *big.Int) error {
+	if profile == nil {
+		profile = paillier.DefaultProfile
+	}
+	err := profile.CheckPaillierModulus(N)
+	if err != nil {
+		return err
+	}
+	err = profile.CheckRingPedersenModulus(NTilde)
+	if err != nil {
+		return err
+	}
+	for _, h := range []*big.Int{h1, h2} {
+		if h == nil || h.Cmp(one) != 1 || h.Cmp(NTilde) != -1 {
+			return fmt.Errorf("ring-pedersen parameter out of range")
+		}
+	}
+	if h1.Cmp(h2) == 0 {
+		return fmt.Errorf("ring-pedersen parameters are equal")
+	}
+	return nil
+}
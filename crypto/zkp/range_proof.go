@@ -15,11 +15,20 @@ type (
 )
 
 // https://eprint.iacr.org/2019/114.pdf A.1 Range Proof
-// RangeProve
+// RangeProve m < q^3 with the secp256k1 order q
 func RangeProve(pk *paillier.PublicKey, NTilde, h1, h2, c, r, m *big.Int) (*RangeProof, error) {
-	if pk == nil || NTilde == nil || h1 == nil || h2 == nil || c == nil || r == nil || m == nil {
+	return RangeProveWithOrder(q, pk, NTilde, h1, h2, c, r, m)
+}
+
+// RangeProveWithOrder q is the curve order, eg: elliptic.P256().Params().N
+func RangeProveWithOrder(q *big.Int, pk *paillier.PublicKey, NTilde, h1, h2, c, r, m *big.Int) (*RangeProof, error) {
+	if q == nil || pk == nil || NTilde == nil || h1 == nil || h2 == nil || c == nil || r == nil || m == nil {
 		return nil, fmt.Errorf("RangeProve parameters error")
 	}
+	err := CheckOrder(q, pk.N)
+	if err != nil {
+		return nil, err
+	}
 
 	q2 := new(big.Int).Mul(q, q)
 	q3 := new(big.Int).Mul(q2, q)
@@ -69,9 +78,22 @@ func RangeProve(pk *paillier.PublicKey, NTilde, h1, h2, c, r, m *big.Int) (*Rang
 	return &RangeProof{Z: z, U: u, W: w, S: s, S1: s1, S2: s2}, nil
 }
 
-// RangeVerify
+// RangeVerify N and NTilde must meet paillier.DefaultProfile
 func RangeVerify(rp *RangeProof, pk *paillier.PublicKey, NTilde, h1, h2, c *big.Int) bool {
-	if rp == nil || pk == nil || NTilde == nil || h1 == nil || h2 == nil || c == nil {
+	return RangeVerifyWithProfile(rp, pk, NTilde, h1, h2, c, paillier.DefaultProfile)
+}
+
+// RangeVerifyWithProfile N and NTilde must meet the minimum sizes of profile
+func RangeVerifyWithProfile(rp *RangeProof, pk *paillier.PublicKey, NTilde, h1, h2, c *big.Int, profile *paillier.SecurityProfile) bool {
+	return RangeVerifyWithOrder(q, rp, pk, NTilde, h1, h2, c, profile)
+}
+
+// RangeVerifyWithOrder q is the curve order used by the prover
+func RangeVerifyWithOrder(q *big.Int, rp *RangeProof, pk *paillier.PublicKey, NTilde, h1, h2, c *big.Int, profile *paillier.SecurityProfile) bool {
+	if q == nil || rp == nil || pk == nil || NTilde == nil || h1 == nil || h2 == nil || c == nil {
+		return false
+	}
+	if checkStatement(profile, pk.N, NTilde, h1, h2) != nil || CheckOrder(q, pk.N) != nil {
 		return false
 	}
 
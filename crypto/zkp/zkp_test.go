@@ -1,6 +1,7 @@
 package zkp
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"testing"
@@ -13,17 +14,11 @@ import (
 
 func TestZkpProof(t *testing.T) {
 	// -----------------------GeneratePreParams-------------------------------
-	concurrency := 4
-	var values = make(chan *big.Int, concurrency)
-	var Pi, Qi *big.Int
-	for Pi == Qi {
-		var quit = make(chan int)
-		for i := 0; i < concurrency; i++ {
-			go crypto.GenerateSafePrime(1024, values, quit)
-		}
-		Pi, Qi = <-values, <-values
-		close(quit)
+	primes, err := crypto.GenerateSafePrimes(context.Background(), 1024, 2, &crypto.SafePrimeOptions{Concurrency: 4})
+	if err != nil {
+		t.Fatal(err)
 	}
+	Pi, Qi := primes[0], primes[1]
 
 	NTildei := new(big.Int).Mul(Pi, Qi)
 	// Compute pi = (Pi-1)/2, qi = (Qi-1)/2
@@ -80,3 +75,14 @@ func TestZkpProof(t *testing.T) {
 	verify = RangeVerify(rangeProof, paiPub, NTildei, h1i, h2i, Ex)
 	fmt.Println(verify)
 }
+
+func TestCheckOrder(t *testing.T) {
+	N := new(big.Int).Lsh(big.NewInt(1), 2047)
+	if err := CheckOrder(secp256k1.S256().N, N); err != nil {
+		t.Fatal(err)
+	}
+	q := new(big.Int).Lsh(big.NewInt(1), 520)
+	if err := CheckOrder(q, N); err == nil {
+		t.Fatal("expected error for small paillier modulus")
+	}
+}
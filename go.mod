@@ -6,4 +6,7 @@ require (
 	github.com/agl/ed25519 v0.0.0-20170116200512-5312a6153412
 	github.com/decred/dcrd/dcrec/edwards/v2 v2.0.2
 	github.com/decred/dcrd/dcrec/secp256k1/v2 v2.0.1
+	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e
 )
+
+require golang.org/x/sys v0.0.0-20210615035016-665e8c7367d1 // indirect
@@ -0,0 +1,220 @@
+package address
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ripemd160"
+	"golang.org/x/crypto/sha3"
+)
+
+// BtcParams bitcoin network address parameters
+type BtcParams struct {
+	PubKeyHashAddrID byte   // P2PKH version byte
+	Bech32HRP        string // segwit human-readable part
+}
+
+var (
+	BtcMainNet = &BtcParams{PubKeyHashAddrID: 0x00, Bech32HRP: "bc"}
+	BtcTestNet = &BtcParams{PubKeyHashAddrID: 0x6f, Bech32HRP: "tb"}
+)
+
+const (
+	tronAddrID = 0x41
+	// Aptos single Ed25519 authentication key scheme
+	aptosEd25519Scheme = 0x00
+	// Sui Ed25519 signature scheme flag
+	suiEd25519Flag = 0x00
+)
+
+// BtcP2PKH base58check(version || hash160(compressed publicKey))
+func BtcP2PKH(publicKey *curves.ECPoint, params *BtcParams) (string, error) {
+	pubKeyBytes, err := secp256k1Compressed(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return base58CheckEncode(params.PubKeyHashAddrID, hash160(pubKeyBytes)), nil
+}
+
+// BtcP2WPKH bech32 witness v0 program hash160(compressed publicKey)
+func BtcP2WPKH(publicKey *curves.ECPoint, params *BtcParams) (string, error) {
+	pubKeyBytes, err := secp256k1Compressed(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return segwitEncode(params.Bech32HRP, 0, hash160(pubKeyBytes))
+}
+
+// BtcP2TR bech32m witness v1 program, BIP86 key path only output key Q = P + H_TapTweak(P)*G
+func BtcP2TR(publicKey *curves.ECPoint, params *BtcParams) (string, error) {
+	// same publicKey checks as P2PKH and P2WPKH
+	if _, err := secp256k1Compressed(publicKey); err != nil {
+		return "", err
+	}
+	curve := publicKey.Curve
+	// internal key with even y
+	P := publicKey
+	if P.Y.Bit(0) == 1 {
+		P = &curves.ECPoint{Curve: curve, X: P.X, Y: new(big.Int).Sub(curve.Params().P, P.Y)}
+	}
+	xBytes := padTo32(P.X.Bytes())
+	t := new(big.Int).SetBytes(taggedHash("TapTweak", xBytes))
+	if t.Cmp(curve.Params().N) >= 0 {
+		return "", fmt.Errorf("BtcP2TR error, tweak out of range")
+	}
+	Q, err := P.Add(curves.ScalarToPoint(curve, t))
+	if err != nil {
+		return "", err
+	}
+	return segwitEncode(params.Bech32HRP, 1, padTo32(Q.X.Bytes()))
+}
+
+// EthAddress EIP-55 checksum of keccak256(uncompressed publicKey)[12:]
+func EthAddress(publicKey *curves.ECPoint) (string, error) {
+	addr, err := ethAddressBytes(publicKey)
+	if err != nil {
+		return "", err
+	}
+	lower := hex.EncodeToString(addr)
+	hash := keccak256([]byte(lower))
+	var sb strings.Builder
+	sb.WriteString("0x")
+	for i, c := range lower {
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if c >= 'a' && nibble&0x0f >= 8 {
+			sb.WriteRune(c - 'a' + 'A')
+		} else {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String(), nil
+}
+
+// TronAddress base58check(0x41 || keccak256(uncompressed publicKey)[12:])
+func TronAddress(publicKey *curves.ECPoint) (string, error) {
+	addr, err := ethAddressBytes(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return base58CheckEncode(tronAddrID, addr), nil
+}
+
+// CosmosAddress bech32(hrp, hash160(compressed publicKey)), eg: hrp "cosmos"
+func CosmosAddress(publicKey *curves.ECPoint, hrp string) (string, error) {
+	pubKeyBytes, err := secp256k1Compressed(publicKey)
+	if err != nil {
+		return "", err
+	}
+	data, err := convertBits(hash160(pubKeyBytes), 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return bech32Encode(hrp, data, bech32Const), nil
+}
+
+// SolanaAddress base58(ed25519 publicKey)
+func SolanaAddress(publicKey *curves.ECPoint) (string, error) {
+	pubKeyBytes, err := ed25519Bytes(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return base58Encode(pubKeyBytes), nil
+}
+
+// AptosAddress sha3-256(ed25519 publicKey || 0x00)
+func AptosAddress(publicKey *curves.ECPoint) (string, error) {
+	pubKeyBytes, err := ed25519Bytes(publicKey)
+	if err != nil {
+		return "", err
+	}
+	hash := sha3.Sum256(append(pubKeyBytes, aptosEd25519Scheme))
+	return "0x" + hex.EncodeToString(hash[:]), nil
+}
+
+// SuiAddress blake2b-256(0x00 || ed25519 publicKey)
+func SuiAddress(publicKey *curves.ECPoint) (string, error) {
+	pubKeyBytes, err := ed25519Bytes(publicKey)
+	if err != nil {
+		return "", err
+	}
+	hash := blake2b.Sum256(append([]byte{suiEd25519Flag}, pubKeyBytes...))
+	return "0x" + hex.EncodeToString(hash[:]), nil
+}
+
+func secp256k1Compressed(publicKey *curves.ECPoint) ([]byte, error) {
+	if publicKey == nil || curves.GetCurveName(publicKey.Curve) != curves.Secp256k1 {
+		return nil, fmt.Errorf("publicKey is not a secp256k1 point")
+	}
+	if !publicKey.IsOnCurve() {
+		return nil, fmt.Errorf("publicKey not on the curve")
+	}
+	pubKey := secp256k1.PublicKey{Curve: publicKey.Curve, X: publicKey.X, Y: publicKey.Y}
+	return pubKey.SerializeCompressed(), nil
+}
+
+func ed25519Bytes(publicKey *curves.ECPoint) ([]byte, error) {
+	if publicKey == nil || curves.GetCurveName(publicKey.Curve) != curves.Ed25519 {
+		return nil, fmt.Errorf("publicKey is not an ed25519 point")
+	}
+	if !publicKey.IsOnCurve() {
+		return nil, fmt.Errorf("publicKey not on the curve")
+	}
+	pubKey := edwards.PublicKey{Curve: publicKey.Curve, X: publicKey.X, Y: publicKey.Y}
+	return pubKey.SerializeCompressed(), nil
+}
+
+func ethAddressBytes(publicKey *curves.ECPoint) ([]byte, error) {
+	if publicKey == nil || curves.GetCurveName(publicKey.Curve) != curves.Secp256k1 {
+		return nil, fmt.Errorf("publicKey is not a secp256k1 point")
+	}
+	if !publicKey.IsOnCurve() {
+		return nil, fmt.Errorf("publicKey not on the curve")
+	}
+	pubKey := secp256k1.PublicKey{Curve: publicKey.Curve, X: publicKey.X, Y: publicKey.Y}
+	// drop 0x04 prefix
+	hash := keccak256(pubKey.SerializeUncompressed()[1:])
+	return hash[12:], nil
+}
+
+// hash160 ripemd160(sha256(data))
+func hash160(data []byte) []byte {
+	sha := sha256.Sum256(data)
+	hasher := ripemd160.New()
+	hasher.Write(sha[:])
+	return hasher.Sum(nil)
+}
+
+func keccak256(data []byte) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(data)
+	return hasher.Sum(nil)
+}
+
+// taggedHash BIP340 sha256(sha256(tag) || sha256(tag) || msg)
+func taggedHash(tag string, msg []byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	hasher := sha256.New()
+	hasher.Write(tagHash[:])
+	hasher.Write(tagHash[:])
+	hasher.Write(msg)
+	return hasher.Sum(nil)
+}
+
+func padTo32(b []byte) []byte {
+	if len(b) >= 32 {
+		return b
+	}
+	out := make([]byte, 32)
+	copy(out[32-len(b):], b)
+	return out
+}
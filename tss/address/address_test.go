@@ -0,0 +1,110 @@
+package address
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss/key/bip32"
+)
+
+func TestSecp256k1Address(t *testing.T) {
+	// private key 1
+	publicKey := curves.ScalarToPoint(secp256k1.S256(), big.NewInt(1))
+
+	p2pkh, _ := BtcP2PKH(publicKey, BtcMainNet)
+	if p2pkh != "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH" {
+		t.Fatal("p2pkh address error", p2pkh)
+	}
+	p2wpkh, _ := BtcP2WPKH(publicKey, BtcMainNet)
+	if p2wpkh != "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4" {
+		t.Fatal("p2wpkh address error", p2wpkh)
+	}
+	eth, _ := EthAddress(publicKey)
+	if eth != "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf" {
+		t.Fatal("eth address error", eth)
+	}
+	tron, _ := TronAddress(publicKey)
+	if tron != "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC" {
+		t.Fatal("tron address error", tron)
+	}
+	cosmos, _ := CosmosAddress(publicKey, "cosmos")
+	if cosmos != "cosmos1w508d6qejxtdg4y5r3zarvary0c5xw7k6ah60c" {
+		t.Fatal("cosmos address error", cosmos)
+	}
+}
+
+func TestBtcP2TR(t *testing.T) {
+	// BIP86 m/86'/0'/0'/0/0, internal key cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115
+	publicKey, err := curves.EcdsaPubKeyToPoint("02cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2tr, _ := BtcP2TR(publicKey, BtcMainNet)
+	if p2tr != "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr" {
+		t.Fatal("p2tr address error", p2tr)
+	}
+	// odd y internal key gives the same output
+	publicKey, _ = curves.EcdsaPubKeyToPoint("03cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115")
+	p2tr, _ = BtcP2TR(publicKey, BtcMainNet)
+	if p2tr != "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr" {
+		t.Fatal("p2tr address error", p2tr)
+	}
+}
+
+func TestEd25519Address(t *testing.T) {
+	// RFC 8032 test 1 public key
+	publicKey, err := curves.Ed25519PubKeyToPoint("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	solana, _ := SolanaAddress(publicKey)
+	if solana != "FVen3X669xLzsi6N2V91DoiyzHzg1uAgqiT8jZ9nS96Z" {
+		t.Fatal("solana address error", solana)
+	}
+	aptos, _ := AptosAddress(publicKey)
+	if aptos != "0x63c5215e87770d17b9f4cd47c777e322f4eb152cfd2054c1080fd9d57c48913b" {
+		t.Fatal("aptos address error", aptos)
+	}
+	sui, _ := SuiAddress(publicKey)
+	if sui != "0x304af458e90e97c841685b8cbbc59b909f3e2cf150df590ada4c81452c29737d" {
+		t.Fatal("sui address error", sui)
+	}
+}
+
+func TestCurveMismatch(t *testing.T) {
+	publicKey := curves.ScalarToPoint(edwards.Edwards(), big.NewInt(1))
+	if _, err := EthAddress(publicKey); err == nil {
+		t.Fatal("ed25519 point should not produce eth address")
+	}
+	publicKey = curves.ScalarToPoint(secp256k1.S256(), big.NewInt(1))
+	if _, err := SolanaAddress(publicKey); err == nil {
+		t.Fatal("secp256k1 point should not produce solana address")
+	}
+	if _, err := BtcP2TR(nil, BtcMainNet); err == nil {
+		t.Fatal("nil publicKey should not produce p2tr address")
+	}
+	offCurve := &curves.ECPoint{Curve: secp256k1.S256(), X: big.NewInt(1), Y: big.NewInt(1)}
+	if _, err := BtcP2TR(offCurve, BtcMainNet); err == nil {
+		t.Fatal("point not on the curve should not produce p2tr address")
+	}
+}
+
+func TestDerivedAddress(t *testing.T) {
+	curve := secp256k1.S256()
+	x := crypto.RandomNum(curve.N)
+	X := curves.ScalarToPoint(curve, x)
+	tssKey, _ := bip32.NewTssKey(nil, X, hex.EncodeToString([]byte("chaincode")))
+	tssKey, _ = tssKey.NewChildKey(0)
+
+	childX := new(big.Int).Mod(new(big.Int).Add(x, tssKey.PrivateKeyOffset()), curve.N)
+	addr1, _ := EthAddress(tssKey.PublicKey())
+	addr2, _ := EthAddress(curves.ScalarToPoint(curve, childX))
+	if addr1 != addr2 {
+		t.Fatal("derived address mismatch")
+	}
+}
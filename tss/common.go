@@ -16,13 +16,15 @@ type Message struct {
 }
 
 type KeyStep1Data struct {
-	C *commitment.Commitment
+	C     *commitment.Commitment
+	Epoch int // key share generation of the sender
 }
 
 type KeyStep2Data struct {
 	Witness *commitment.Witness
 	Share   *vss.Share // secret share
 	Proof   *schnorr.Proof
+	Epoch   int
 }
 
 type KeyStep3Data struct {
@@ -31,4 +33,6 @@ type KeyStep3Data struct {
 	PublicKey      *curves.ECPoint         // PublicKey
 	ChainCode      string                  // chaincode for derivation, no longer change when update
 	SharePubKeyMap map[int]*curves.ECPoint //  ShareI*G map
+	Epoch          int                     // key share generation, 0 after dkg, increased by each refresh
+	EpochHash      string                  // hash chain of the public outputs of dkg and each refresh, not of the protocol messages
 }
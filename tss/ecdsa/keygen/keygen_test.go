@@ -1,14 +1,25 @@
 package keygen
 
 import (
+	"context"
+	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/okx/threshold-lib/crypto"
 	"github.com/okx/threshold-lib/crypto/curves"
 	"github.com/okx/threshold-lib/crypto/paillier"
 	"github.com/okx/threshold-lib/tss"
 	"github.com/okx/threshold-lib/tss/key/bip32"
 	"github.com/okx/threshold-lib/tss/key/dkg"
+	"github.com/okx/threshold-lib/tss/key/reshare"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 )
 
 const (
@@ -73,3 +84,325 @@ func TestKeyGen(t *testing.T) {
 	fmt.Println(tssKey.PublicKey())
 
 }
+
+func TestEcdsaRefresh(t *testing.T) {
+	setUp1 := dkg.NewSetUp(1, 3, curve)
+	setUp2 := dkg.NewSetUp(2, 3, curve)
+	setUp3 := dkg.NewSetUp(3, 3, curve)
+
+	msgs1_1, _ := setUp1.DKGStep1()
+	msgs2_1, _ := setUp2.DKGStep1()
+	msgs3_1, _ := setUp3.DKGStep1()
+
+	msgs1_2, _ := setUp1.DKGStep2([]*tss.Message{msgs2_1[1], msgs3_1[1]})
+	msgs2_2, _ := setUp2.DKGStep2([]*tss.Message{msgs1_1[2], msgs3_1[2]})
+	msgs3_2, _ := setUp3.DKGStep2([]*tss.Message{msgs1_1[3], msgs2_1[3]})
+
+	p1Data, _ := setUp1.DKGStep3([]*tss.Message{msgs2_2[1], msgs3_2[1]})
+	p2Data, _ := setUp2.DKGStep3([]*tss.Message{msgs1_2[2], msgs3_2[2]})
+	p3Data, _ := setUp3.DKGStep3([]*tss.Message{msgs1_2[3], msgs2_2[3]})
+
+	preParams := &PreParams{}
+	err := json.Unmarshal([]byte(preParamsStr), preParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paiPriKey, _, _ := paillier.NewKeyPair(8)
+
+	// refresh shares by 1, 3, signing pair 1 --> 2
+	devoteList := [2]int{1, 3}
+	refresh1 := NewEcdsaRefresh(reshare.NewRefresh(1, 3, devoteList, p1Data.ShareI, p1Data.PublicKey).SetChainCode(p1Data.ChainCode), 1, 2, paiPriKey, preParams)
+	refresh2 := NewEcdsaRefresh(reshare.NewRefresh(2, 3, devoteList, nil, p2Data.PublicKey).SetChainCode(p2Data.ChainCode), 1, 2, nil, nil)
+	refresh3 := NewEcdsaRefresh(reshare.NewRefresh(3, 3, devoteList, p3Data.ShareI, p3Data.PublicKey).SetChainCode(p3Data.ChainCode), 1, 2, nil, nil)
+
+	msgs1_1, _ = refresh1.DKGStep1()
+	msgs2_1, _ = refresh2.DKGStep1()
+	msgs3_1, _ = refresh3.DKGStep1()
+
+	msgs1_2, _ = refresh1.DKGStep2([]*tss.Message{msgs2_1[1], msgs3_1[1]})
+	msgs2_2, _ = refresh2.DKGStep2([]*tss.Message{msgs1_1[2], msgs3_1[2]})
+	msgs3_2, _ = refresh3.DKGStep2([]*tss.Message{msgs1_1[3], msgs2_1[3]})
+
+	p1Msgs, err := refresh1.DKGStep3([]*tss.Message{msgs2_2[1], msgs3_2[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = refresh2.DKGStep3([]*tss.Message{msgs1_2[2], msgs3_2[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = refresh3.DKGStep3([]*tss.Message{msgs1_2[3], msgs2_2[3]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = refresh1.SaveData(); err == nil {
+		t.Fatal("P1 save data should wait for P2 acknowledgement")
+	}
+
+	ack, err := refresh2.P2Step(p1Msgs[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = refresh3.SaveData(); err == nil {
+		t.Fatal("save data should wait for P1 confirmation")
+	}
+	confirms, err := refresh1.P1Finish(ack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = refresh3.Confirm(confirms[2]); err == nil {
+		t.Fatal("confirmation for another device should fail")
+	}
+	for id, refresh := range map[int]*EcdsaRefresh{2: refresh2, 3: refresh3} {
+		if err = refresh.Confirm(confirms[id]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p1SaveData, _ := refresh1.SaveData()
+	p2SaveData, _ := refresh2.SaveData()
+	p3SaveData, _ := refresh3.SaveData()
+	if p1SaveData.PaiPriKey != paiPriKey || p2SaveData.P2SaveData.PaiPubKey.N.Cmp(paiPriKey.N) != 0 {
+		t.Fatal("paillier key rotation error")
+	}
+	if !p3SaveData.KeyData.PublicKey.Equals(p1Data.PublicKey) || p3SaveData.KeyData.ChainCode != p1Data.ChainCode {
+		t.Fatal("refresh key data error")
+	}
+	// new x1 encrypted under the new paillier key
+	x1, _ := paiPriKey.Decrypt(p2SaveData.P2SaveData.E_x1)
+	X1 := curves.ScalarToPoint(curve, x1)
+	X2 := curves.ScalarToPoint(curve, p2SaveData.P2SaveData.X2)
+	X, _ := X1.Add(X2)
+	if !X.Equals(p1Data.PublicKey) {
+		t.Fatal("encrypted x1 error")
+	}
+}
+
+func TestPool(t *testing.T) {
+	store, err := NewFileKeystore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	genPaillier := func(ctx context.Context) (interface{}, error) {
+		return fakePaillierKey(1024)
+	}
+	genPreParams := func(ctx context.Context) (interface{}, error) {
+		preParams := &PreParams{}
+		err := json.Unmarshal([]byte(preParamsStr), preParams)
+		return preParams, err
+	}
+
+	ctx := context.Background()
+	pool, err := newPool(ctx, 2, store, paillier.DefaultProfile, genPaillier, genPreParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		paiPriKey, err := pool.PaillierKey(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[paiPriKey.N.String()] {
+			t.Fatal("paillier key handed out twice")
+		}
+		seen[paiPriKey.N.String()] = true
+	}
+	// wait until the pool is refilled and persisted
+	for {
+		paiNum, preNum := pool.Len()
+		if paiNum == 2 && preNum == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	pool.Close()
+
+	// a new pool loads the persisted items, generation is blocked
+	blocked := func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	pool, err = newPool(ctx, 2, store, paillier.DefaultProfile, blocked, blocked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		paiPriKey, err := pool.PaillierKey(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[paiPriKey.N.String()] {
+			t.Fatal("paillier key handed out twice")
+		}
+		seen[paiPriKey.N.String()] = true
+		_, err = pool.PreParams(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	keys, _ := store.List("")
+	if len(keys) != 0 {
+		t.Fatal("handed out items must be deleted from the keystore", keys)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = pool.PaillierKey(timeout)
+	if err != context.DeadlineExceeded {
+		t.Fatal("empty pool should respect context cancellation", err)
+	}
+	pool.Close()
+}
+
+func TestPoolRetry(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileKeystore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	failures := 2
+	genPaillier := func(ctx context.Context) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			return nil, fmt.Errorf("generation failed")
+		}
+		return &paillier.PrivateKey{PublicKey: paillier.PublicKey{N: big.NewInt(1)}}, nil
+	}
+	genPreParams := func(ctx context.Context) (interface{}, error) {
+		return &PreParams{NTildei: big.NewInt(1)}, nil
+	}
+
+	ctx := context.Background()
+	pool, err := newPool(ctx, 1, store, paillier.DefaultProfile, genPaillier, genPreParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = pool.PaillierKey(timeout)
+	if !errors.Is(err, context.DeadlineExceeded) || pool.Err() == nil {
+		t.Fatal("generation error should be reported", err)
+	}
+	// retried after 100ms and 200ms
+	_, err = pool.PaillierKey(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool.Err() != nil {
+		t.Fatal("error should be cleared after a success", pool.Err())
+	}
+
+	for {
+		if paiNum, _ := pool.Len(); paiNum == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	keys, _ := store.List(paillierPrefix)
+	info, err := os.Stat(filepath.Join(dir, keys[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatal("keystore file mode error", info.Mode())
+	}
+}
+
+// fakePaillierKey N = p*q of two random primes, enough for the pool validation
+func fakePaillierKey(primeBits int) (*paillier.PrivateKey, error) {
+	p, err := rand.Prime(rand.Reader, primeBits)
+	if err != nil {
+		return nil, err
+	}
+	q, err := rand.Prime(rand.Reader, primeBits)
+	if err != nil {
+		return nil, err
+	}
+	return &paillier.PrivateKey{PublicKey: paillier.PublicKey{N: new(big.Int).Mul(p, q)}}, nil
+}
+
+func TestPoolValidate(t *testing.T) {
+	store, err := NewFileKeystore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2048-bit items stored under the 3072 profile
+	paiPriKey, err := fakePaillierKey(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	suffix := fmt.Sprintf("%d-%d-", paillier.Profile3072.PaillierBits, paillier.Profile3072.RingPedersenBits)
+	data, _ := json.Marshal(paiPriKey)
+	_ = store.Put(paillierPrefix+suffix+"00112233445566778899aabbccddeeff", data)
+	_ = store.Put(preParamsPrefix+suffix+"00112233445566778899aabbccddeeff", []byte(preParamsStr))
+	_ = store.Put(preParamsPrefix+suffix+"ffeeddccbbaa99887766554433221100", []byte("{"))
+
+	blocked := func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	pool, err := newPool(context.Background(), 2, store, paillier.Profile3072, blocked, blocked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	if paiNum, preNum := pool.Len(); paiNum != 0 || preNum != 0 {
+		t.Fatal("items below the profile must not be loaded")
+	}
+	keys, _ := store.List("")
+	if len(keys) != 0 {
+		t.Fatal("invalid items should be deleted from the keystore", keys)
+	}
+}
+
+func TestKeyGenProfile(t *testing.T) {
+	// shares of f(x) = a0 + a1*x for devices 1 and 2
+	a0, a1 := crypto.RandomNum(curve.N), crypto.RandomNum(curve.N)
+	share1 := new(big.Int).Mod(new(big.Int).Add(a0, a1), curve.N)
+	share2 := new(big.Int).Mod(new(big.Int).Add(a0, new(big.Int).Lsh(a1, 1)), curve.N)
+	publicKey := curves.ScalarToPoint(curve, a0)
+
+	ctx := context.Background()
+	paiPriKey3072, _, err := paillier.NewKeyPairWithProfile(ctx, paillier.Profile3072, runtime.NumCPU())
+	if err != nil {
+		t.Fatal(err)
+	}
+	preParams3072, err := GeneratePreParamsWithProfile(ctx, paillier.Profile3072)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := P1WithProfile(share1, paiPriKey3072, 1, 2, preParams3072, paillier.Profile3072)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = P2WithProfile(share2, publicKey, msg, 1, 2, paillier.Profile3072)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a larger profile meets the default minimum
+	_, err = P2(share2, publicKey, msg, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preParams := &PreParams{}
+	_ = json.Unmarshal([]byte(preParamsStr), preParams)
+	paiPriKey, _, _ := paillier.NewKeyPair(8)
+	_, err = P1WithProfile(share1, paiPriKey, 1, 2, preParams, paillier.Profile3072)
+	if err == nil {
+		t.Fatal("2048-bit paillier key should not meet the 3072 profile")
+	}
+	msg, err = P1(share1, paiPriKey, 1, 2, preParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = P2WithProfile(share2, publicKey, msg, 1, 2, paillier.Profile3072)
+	if err == nil {
+		t.Fatal("P2 should refuse a profile below its minimum")
+	}
+}
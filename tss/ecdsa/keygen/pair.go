@@ -0,0 +1,136 @@
+package keygen
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/paillier"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// PairData paillier material of one device for every pair, the lower id of a pair is always P1
+// the device acts as P1 with its own paillier key towards higher ids, or as P2 with P2SaveData received from lower ids
+type PairData struct {
+	Id         int
+	PaiPriKey  *paillier.PrivateKey // own paillier key, used when acting as P1, nil for the highest id
+	P2SaveData map[int]*P2SaveData  // key: lower peer acting as P1
+}
+
+// PairKeyGen after dkg, every device acts as P1 towards every higher device in ids
+type PairKeyGen struct {
+	id        int
+	ids       []int
+	share     *big.Int
+	publicKey *curves.ECPoint
+	paiPriKey *paillier.PrivateKey
+	preParams *PreParams
+}
+
+// NewPairKeyGen ids are the devices to pair with, including this device
+// paiPriKey and preParams are only used towards higher ids, paiPriKey may be nil for the highest id
+func NewPairKeyGen(id int, ids []int, share *big.Int, publicKey *curves.ECPoint, paiPriKey *paillier.PrivateKey, preParams *PreParams) *PairKeyGen {
+	if share == nil || publicKey == nil || len(ids) < 2 {
+		panic(fmt.Errorf("NewPairKeyGen params error"))
+	}
+	found, highest := false, true
+	for _, v := range ids {
+		if v == id {
+			found = true
+		}
+		if v > id {
+			highest = false
+		}
+	}
+	if !found {
+		panic(fmt.Errorf("NewPairKeyGen params error, id not in ids"))
+	}
+	if paiPriKey == nil && !highest {
+		panic(fmt.Errorf("NewPairKeyGen params error, paillier key is required"))
+	}
+	if preParams == nil && !highest {
+		preParams = GeneratePreParams()
+	}
+	return &PairKeyGen{
+		id:        id,
+		ids:       ids,
+		share:     share,
+		publicKey: publicKey,
+		paiPriKey: paiPriKey,
+		preParams: preParams,
+	}
+}
+
+// Step1 send encrypted x1 to every higher device, same as P1
+func (pk *PairKeyGen) Step1() (map[int]*tss.Message, error) {
+	out := make(map[int]*tss.Message, len(pk.ids)-1)
+	for _, to := range pk.ids {
+		if to <= pk.id {
+			continue
+		}
+		msg, err := P1WithCurve(pk.publicKey.Curve, pk.share, pk.paiPriKey, pk.id, to, pk.preParams, paillier.DefaultProfile)
+		if err != nil {
+			return nil, err
+		}
+		out[to] = msg
+	}
+	return out, nil
+}
+
+// Step2 verify encrypted x1 from every lower device, same as P2
+func (pk *PairKeyGen) Step2(msgs []*tss.Message) (*PairData, error) {
+	var lower []int
+	for _, id := range pk.ids {
+		if id < pk.id {
+			lower = append(lower, id)
+		}
+	}
+	if len(msgs) != len(lower) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	pairData := &PairData{
+		Id:         pk.id,
+		PaiPriKey:  pk.paiPriKey,
+		P2SaveData: make(map[int]*P2SaveData, len(msgs)),
+	}
+	for _, msg := range msgs {
+		if msg.From >= pk.id {
+			return nil, fmt.Errorf("message from %d, only lower ids act as P1", msg.From)
+		}
+		if _, ok := pairData.P2SaveData[msg.From]; ok {
+			return nil, fmt.Errorf("duplicate message from %d", msg.From)
+		}
+		p2SaveData, err := P2(pk.share, pk.publicKey, msg, msg.From, pk.id)
+		if err != nil {
+			return nil, err
+		}
+		pairData.P2SaveData[msg.From] = p2SaveData
+	}
+	for _, id := range lower {
+		if _, ok := pairData.P2SaveData[id]; !ok {
+			return nil, fmt.Errorf("missing message from %d", id)
+		}
+	}
+	return pairData, nil
+}
+
+// IsP1 role of this device when signing with the pair (i, j), the lower id is P1 whatever the order of i and j
+func (data *PairData) IsP1(i, j int) (bool, error) {
+	if i == j || (data.Id != i && data.Id != j) {
+		return false, fmt.Errorf("device %d not in pair (%d, %d)", data.Id, i, j)
+	}
+	peer := j
+	if data.Id == j {
+		peer = i
+	}
+	if data.Id < peer {
+		if data.PaiPriKey == nil {
+			return false, fmt.Errorf("no paillier key for pair (%d, %d)", i, j)
+		}
+		return true, nil
+	}
+	if _, ok := data.P2SaveData[peer]; !ok {
+		return false, fmt.Errorf("no P2SaveData for pair (%d, %d)", i, j)
+	}
+	return false, nil
+}
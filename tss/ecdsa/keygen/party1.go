@@ -1,6 +1,8 @@
 package keygen
 
 import (
+	"context"
+	"crypto/elliptic"
 	"encoding/json"
 	"fmt"
 	"math/big"
@@ -16,6 +18,7 @@ import (
 )
 
 var (
+	// curve default curve of P1 and P1WithProfile, P2 takes the curve of the public key
 	curve = secp256k1.S256()
 	G     = curves.ScalarToPoint(curve, big.NewInt(1))
 )
@@ -29,17 +32,29 @@ type PreParams struct {
 
 // GeneratePreParams  recommend to pre-generate locally
 func GeneratePreParams() *PreParams {
-	concurrency := 4
-	var values = make(chan *big.Int, concurrency)
-	var Pi, Qi *big.Int
-	for Pi == Qi {
-		var quit = make(chan int)
-		for i := 0; i < concurrency; i++ {
-			go crypto.GenerateSafePrime(1024, values, quit)
-		}
-		Pi, Qi = <-values, <-values
-		close(quit)
+	preParams, err := GeneratePreParamsWithContext(context.Background())
+	if err != nil {
+		panic(fmt.Errorf("GeneratePreParams error, %v", err))
+	}
+	return preParams
+}
+
+// GeneratePreParamsWithContext stops when ctx is done
+func GeneratePreParamsWithContext(ctx context.Context) (*PreParams, error) {
+	return GeneratePreParamsWithProfile(ctx, paillier.DefaultProfile)
+}
+
+// GeneratePreParamsWithProfile NTilde of profile.RingPedersenBits
+func GeneratePreParamsWithProfile(ctx context.Context, profile *paillier.SecurityProfile) (*PreParams, error) {
+	err := profile.Check()
+	if err != nil {
+		return nil, err
+	}
+	primes, err := crypto.GenerateSafePrimes(ctx, profile.RingPedersenBits/2, 2, &crypto.SafePrimeOptions{Concurrency: 4})
+	if err != nil {
+		return nil, err
 	}
+	Pi, Qi := primes[0], primes[1]
 
 	NTildei := new(big.Int).Mul(Pi, Qi)
 	// Compute pi = (Pi-1)/2, qi = (Qi-1)/2
@@ -64,7 +79,7 @@ func GeneratePreParams() *PreParams {
 		P:       pi,
 		Q:       qi,
 	}
-	return preParams
+	return preParams, nil
 }
 
 type P1Data struct {
@@ -78,16 +93,44 @@ type P1Data struct {
 	DlnProof2       *zkp.DlnProof
 	PDLwSlackProof  *zkp.PDLwSlackProof
 	StatementParams *zkp.StatementParams
+	Profile         *paillier.SecurityProfile // declared moduli sizes
 }
 
 // P1 after dkg, prepare for 2-party signature, P1 send encrypt x1 to P2
-// paillier key pair generation is time-consuming, generated in advance, encrypted storage?
+// paillier key pair and preParams generation is time-consuming, take them from a Pool
+// nil preParams are generated on the spot
 func P1(share1 *big.Int, paiPriKey *paillier.PrivateKey, from, to int, preParams *PreParams) (*tss.Message, error) {
+	return P1WithProfile(share1, paiPriKey, from, to, preParams, paillier.DefaultProfile)
+}
+
+// P1WithProfile paillier key and preParams must meet the profile, which is sent to P2 for validation
+func P1WithProfile(share1 *big.Int, paiPriKey *paillier.PrivateKey, from, to int, preParams *PreParams, profile *paillier.SecurityProfile) (*tss.Message, error) {
+	return P1WithCurve(curve, share1, paiPriKey, from, to, preParams, profile)
+}
+
+// P1WithCurve shares from dkg on curve, eg: elliptic.P256(), the curve must be registered in curves
+// the paillier modulus must be large enough for the curve order, see zkp.CheckOrder
+func P1WithCurve(curve elliptic.Curve, share1 *big.Int, paiPriKey *paillier.PrivateKey, from, to int, preParams *PreParams, profile *paillier.SecurityProfile) (*tss.Message, error) {
+	if curves.GetCurveName(curve) == "" {
+		return nil, fmt.Errorf("unsupported curve")
+	}
+	err := profile.Check()
+	if err != nil {
+		return nil, err
+	}
+	err = paiPriKey.ValidateWithProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	err = zkp.CheckOrder(curve.Params().N, paiPriKey.N)
+	if err != nil {
+		return nil, err
+	}
 	// lagrangian interpolation x1
 	x1 := vss.CalLagrangian(curve, big.NewInt(int64(from)), share1, []*big.Int{big.NewInt(int64(from)), big.NewInt(int64(to))})
 	paiPubKey := &paiPriKey.PublicKey
 	// paillier encrypt x1
-	E_x1, r, err := paiPubKey.Encrypt(x1)
+	E_x1, r, err := paiPriKey.Encrypt(x1)
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +146,14 @@ func P1(share1 *big.Int, paiPriKey *paillier.PrivateKey, from, to int, preParams
 	}
 
 	if preParams == nil {
-		preParams = GeneratePreParams()
+		preParams, err = GeneratePreParamsWithProfile(context.Background(), profile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = profile.CheckRingPedersenModulus(preParams.NTildei)
+	if err != nil {
+		return nil, err
 	}
 	h1i, h2i, alpha, beta, p, q, NTildei :=
 		preParams.H1i,
@@ -126,10 +176,11 @@ func P1(share1 *big.Int, paiPriKey *paillier.PrivateKey, from, to int, preParams
 		N:          paiPubKey.N,
 		CipherText: E_x1,
 		Q:          X1,
-		G:          G,
+		G:          curves.ScalarToPoint(curve, big.NewInt(1)),
 		H1:         h1i,
 		H2:         h2i,
 		NTilde:     NTildei,
+		Profile:    profile,
 	}
 	pdlWSlackPf, statementParams := zkp.NewPDLwSlackProve(pdlWSlackWitness, pdlWSlackStatement)
 	if pdlWSlackPf == nil || statementParams == nil {
@@ -146,6 +197,7 @@ func P1(share1 *big.Int, paiPriKey *paillier.PrivateKey, from, to int, preParams
 		DlnProof2:       dlnProof2,
 		PDLwSlackProof:  pdlWSlackPf,
 		StatementParams: statementParams,
+		Profile:         profile,
 	}
 	bytes, err := json.Marshal(p1Data)
 	if err != nil {
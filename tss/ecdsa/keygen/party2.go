@@ -23,14 +23,28 @@ type P2SaveData struct {
 
 // P2 after dkg, prepare for 2-party signature, P2 receives encrypt x1 and paillier public key from P1
 func P2(share2 *big.Int, publicKey *curves.ECPoint, msg *tss.Message, from, to int) (*P2SaveData, error) {
+	return P2WithProfile(share2, publicKey, msg, from, to, paillier.DefaultProfile)
+}
+
+// P2WithProfile minProfile is the smallest moduli sizes P2 accepts, the profile declared by P1 must meet it
+func P2WithProfile(share2 *big.Int, publicKey *curves.ECPoint, msg *tss.Message, from, to int, minProfile *paillier.SecurityProfile) (*P2SaveData, error) {
+	err := minProfile.Check()
+	if err != nil {
+		return nil, err
+	}
 	if msg.From != from || msg.To != to {
 		return nil, fmt.Errorf("message mismatch")
 	}
 	p1Data := &P1Data{}
-	err := json.Unmarshal([]byte(msg.Data), p1Data)
+	err = json.Unmarshal([]byte(msg.Data), p1Data)
 	if err != nil {
 		return nil, err
 	}
+	// P1 shares must be on the curve of the public key
+	curve := publicKey.Curve
+	if p1Data.X1 == nil || curves.GetCurveName(p1Data.X1.Curve) != curves.GetCurveName(curve) {
+		return nil, fmt.Errorf("curve mismatch")
+	}
 	// lagrangian interpolation x2, x = x1 + x2
 	x2 := vss.CalLagrangian(curve, big.NewInt(int64(to)), share2, []*big.Int{big.NewInt(int64(from)), big.NewInt(int64(to))})
 	X2 := curves.ScalarToPoint(curve, x2)
@@ -45,10 +59,30 @@ func P2(share2 *big.Int, publicKey *curves.ECPoint, msg *tss.Message, from, to i
 	if !verify {
 		return nil, fmt.Errorf("schnorr signature verification error")
 	}
-	// checking paillier keys correct size
-	bitlen := p1Data.PaiPubKey.N.BitLen()
-	if bitlen != paillier.PrimeBits && bitlen != paillier.PrimeBits-1 {
-		return nil, fmt.Errorf("invalid paillier keys")
+	// security profile declared by P1, messages without a profile use the default
+	profile := p1Data.Profile
+	if profile == nil {
+		profile = paillier.DefaultProfile
+	}
+	err = profile.Check()
+	if err != nil {
+		return nil, err
+	}
+	if !profile.AtLeast(minProfile) {
+		return nil, fmt.Errorf("security profile below the minimum, paillier %d ring-pedersen %d", profile.PaillierBits, profile.RingPedersenBits)
+	}
+	// checking paillier public key and encrypted x1
+	err = p1Data.PaiPubKey.ValidateWithProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	err = p1Data.PaiPubKey.ValidateCiphertext(p1Data.E_x1)
+	if err != nil {
+		return nil, err
+	}
+	err = zkp.CheckOrder(curve.Params().N, p1Data.PaiPubKey.N)
+	if err != nil {
+		return nil, err
 	}
 	nizkVerify := paillier.NIZKVerify(p1Data.PaiPubKey.N, p1Data.NIZKProof)
 	if !nizkVerify {
@@ -71,10 +105,11 @@ func P2(share2 *big.Int, publicKey *curves.ECPoint, msg *tss.Message, from, to i
 		N:          p1Data.PaiPubKey.N,
 		CipherText: p1Data.E_x1,
 		Q:          p1Data.X1,
-		G:          G,
+		G:          curves.ScalarToPoint(curve, big.NewInt(1)),
 		H1:         h1i,
 		H2:         h2i,
 		NTilde:     NTildei,
+		Profile:    profile,
 	}
 	slackVerify := zkp.PDLwSlackVerify(p1Data.PDLwSlackProof, pdlWSlackStatement)
 	if !slackVerify {
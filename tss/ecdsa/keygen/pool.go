@@ -0,0 +1,396 @@
+package keygen
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/okx/threshold-lib/crypto/paillier"
+)
+
+const (
+	paillierPrefix  = "paillier-"
+	preParamsPrefix = "preparams-"
+)
+
+// retry delay after a failed generation, doubled up to maxRetryDelay
+var (
+	minRetryDelay = 100 * time.Millisecond
+	maxRetryDelay = time.Minute
+)
+
+// Keystore persistent storage of pre-generated key material, encryption at rest is up to the implementation
+type Keystore interface {
+	Put(key string, value []byte) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+	List(prefix string) ([]string, error)
+}
+
+// FileKeystore one file per key in a directory, files are written with mode 0600 in a 0700 directory
+// items are stored as plaintext JSON, including paillier private keys, use a Keystore that encrypts
+// the values if the directory is not protected otherwise
+type FileKeystore struct {
+	dir string
+}
+
+// NewFileKeystore create dir if not exists
+func NewFileKeystore(dir string) (*FileKeystore, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	return &FileKeystore{dir: dir}, nil
+}
+
+// Put write to a temporary file and rename, a crash never leaves a partial item
+func (ks *FileKeystore) Put(key string, value []byte) error {
+	if !validKey(key) {
+		return fmt.Errorf("invalid keystore key %q", key)
+	}
+	tmp, err := os.CreateTemp(ks.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	err = tmp.Chmod(0600)
+	if err == nil {
+		_, err = tmp.Write(value)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(ks.dir, key))
+}
+
+func (ks *FileKeystore) Get(key string) ([]byte, error) {
+	if !validKey(key) {
+		return nil, fmt.Errorf("invalid keystore key %q", key)
+	}
+	return os.ReadFile(filepath.Join(ks.dir, key))
+}
+
+func (ks *FileKeystore) Delete(key string) error {
+	if !validKey(key) {
+		return fmt.Errorf("invalid keystore key %q", key)
+	}
+	return os.Remove(filepath.Join(ks.dir, key))
+}
+
+// List sorted keys with the prefix
+func (ks *FileKeystore) List(prefix string) ([]string, error) {
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, prefix) && validKey(name) {
+			keys = append(keys, name)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func validKey(key string) bool {
+	return key != "" && !strings.HasPrefix(key, ".") && !strings.ContainsAny(key, `/\`)
+}
+
+// Pool generates paillier keys and PreParams in background goroutines up to the target size
+// every item is handed out exactly once, and removed from the keystore before it is returned
+type Pool struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	store  Keystore
+
+	paiKeys   *itemPool
+	preParams *itemPool
+}
+
+type itemPool struct {
+	prefix   string
+	generate func(ctx context.Context) (interface{}, error)
+	validate func(data []byte) error // items loaded from the store
+	items    chan *poolItem
+	slots    chan struct{} // free places, items + slots = target size
+
+	errLock sync.Mutex
+	err     error // last generation error, nil after a success
+}
+
+type poolItem struct {
+	key  string
+	data []byte
+}
+
+// NewPool size is the target number of each item kind, store is optional, nil keeps items in memory only
+// items left in the store by a previous pool are loaded first
+func NewPool(ctx context.Context, size int, store Keystore) (*Pool, error) {
+	return NewPoolWithProfile(ctx, size, store, paillier.DefaultProfile)
+}
+
+// NewPoolWithProfile items of the profile sizes, pools of different profiles can share a store
+func NewPoolWithProfile(ctx context.Context, size int, store Keystore, profile *paillier.SecurityProfile) (*Pool, error) {
+	err := profile.Check()
+	if err != nil {
+		return nil, err
+	}
+	return newPool(ctx, size, store, profile,
+		func(ctx context.Context) (interface{}, error) {
+			paiPriKey, _, err := paillier.NewKeyPairWithProfile(ctx, profile, runtime.NumCPU())
+			return paiPriKey, err
+		},
+		func(ctx context.Context) (interface{}, error) {
+			return GeneratePreParamsWithProfile(ctx, profile)
+		})
+}
+
+func newPool(ctx context.Context, size int, store Keystore, profile *paillier.SecurityProfile, genPaillier, genPreParams func(ctx context.Context) (interface{}, error)) (*Pool, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("pool size must be positive")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	suffix := fmt.Sprintf("%d-%d-", profile.PaillierBits, profile.RingPedersenBits)
+	pool := &Pool{
+		ctx:       ctx,
+		cancel:    cancel,
+		store:     store,
+		paiKeys:   newItemPool(paillierPrefix+suffix, size, genPaillier),
+		preParams: newItemPool(preParamsPrefix+suffix, size, genPreParams),
+	}
+	pool.paiKeys.validate = func(data []byte) error {
+		paiPriKey := &paillier.PrivateKey{}
+		err := json.Unmarshal(data, paiPriKey)
+		if err != nil {
+			return err
+		}
+		return paiPriKey.ValidateWithProfile(profile)
+	}
+	pool.preParams.validate = func(data []byte) error {
+		preParams := &PreParams{}
+		err := json.Unmarshal(data, preParams)
+		if err != nil {
+			return err
+		}
+		return profile.CheckRingPedersenModulus(preParams.NTildei)
+	}
+	for _, ip := range []*itemPool{pool.paiKeys, pool.preParams} {
+		err := pool.load(ip)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+	for _, ip := range []*itemPool{pool.paiKeys, pool.preParams} {
+		pool.wg.Add(1)
+		go pool.run(ip)
+	}
+	return pool, nil
+}
+
+func newItemPool(prefix string, size int, generate func(ctx context.Context) (interface{}, error)) *itemPool {
+	ip := &itemPool{
+		prefix:   prefix,
+		generate: generate,
+		items:    make(chan *poolItem, size),
+		slots:    make(chan struct{}, size),
+	}
+	for i := 0; i < size; i++ {
+		ip.slots <- struct{}{}
+	}
+	return ip
+}
+
+// load stored items up to the target size, the rest stay in the store for a later pool
+// items that do not meet the profile are deleted from the store and never handed out
+func (p *Pool) load(ip *itemPool) error {
+	if p.store == nil {
+		return nil
+	}
+	keys, err := p.store.List(ip.prefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if len(ip.slots) == 0 {
+			break
+		}
+		data, err := p.store.Get(key)
+		if err != nil {
+			return err
+		}
+		if ip.validate(data) != nil {
+			err = p.store.Delete(key)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		<-ip.slots
+		ip.items <- &poolItem{key: key, data: data}
+	}
+	return nil
+}
+
+// run fill free slots until the pool is closed, failed generations are retried with exponential backoff
+func (p *Pool) run(ip *itemPool) {
+	defer p.wg.Done()
+	delay := minRetryDelay
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ip.slots:
+		}
+		item, err := p.newItem(ip)
+		ip.setErr(err)
+		if err != nil {
+			// give the slot back and retry
+			ip.slots <- struct{}{}
+			select {
+			case <-p.ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay *= 2
+			if delay > maxRetryDelay {
+				delay = maxRetryDelay
+			}
+			continue
+		}
+		delay = minRetryDelay
+		ip.items <- item
+	}
+}
+
+func (ip *itemPool) setErr(err error) {
+	ip.errLock.Lock()
+	defer ip.errLock.Unlock()
+	ip.err = err
+}
+
+func (ip *itemPool) lastErr() error {
+	ip.errLock.Lock()
+	defer ip.errLock.Unlock()
+	return ip.err
+}
+
+func (p *Pool) newItem(ip *itemPool) (*poolItem, error) {
+	value, err := ip.generate(p.ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	_, err = rand.Read(id)
+	if err != nil {
+		return nil, err
+	}
+	item := &poolItem{key: ip.prefix + hex.EncodeToString(id), data: data}
+	if p.store != nil {
+		err = p.store.Put(item.key, item.data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return item, nil
+}
+
+// take wait for an item, the item is deleted from the store before it is handed out
+func (p *Pool) take(ctx context.Context, ip *itemPool) ([]byte, error) {
+	var item *poolItem
+	select {
+	case item = <-ip.items:
+	default:
+		select {
+		case item = <-ip.items:
+		case <-ctx.Done():
+			if err := ip.lastErr(); err != nil {
+				return nil, fmt.Errorf("%w, last generation error: %v", ctx.Err(), err)
+			}
+			return nil, ctx.Err()
+		case <-p.ctx.Done():
+			return nil, fmt.Errorf("pool closed")
+		}
+	}
+	ip.slots <- struct{}{}
+	if p.store != nil {
+		err := p.store.Delete(item.key)
+		if err != nil {
+			// never hand out an item that may be loaded again
+			return nil, err
+		}
+	}
+	return item.data, nil
+}
+
+// PaillierKey take a pre-generated paillier key, blocks until one is available or ctx is done
+func (p *Pool) PaillierKey(ctx context.Context) (*paillier.PrivateKey, error) {
+	data, err := p.take(ctx, p.paiKeys)
+	if err != nil {
+		return nil, err
+	}
+	paiPriKey := &paillier.PrivateKey{}
+	err = json.Unmarshal(data, paiPriKey)
+	if err != nil {
+		return nil, err
+	}
+	return paiPriKey, nil
+}
+
+// PreParams take pre-generated PreParams, blocks until available or ctx is done
+func (p *Pool) PreParams(ctx context.Context) (*PreParams, error) {
+	data, err := p.take(ctx, p.preParams)
+	if err != nil {
+		return nil, err
+	}
+	preParams := &PreParams{}
+	err = json.Unmarshal(data, preParams)
+	if err != nil {
+		return nil, err
+	}
+	return preParams, nil
+}
+
+// Len available paillier keys and PreParams
+func (p *Pool) Len() (int, int) {
+	return len(p.paiKeys.items), len(p.preParams.items)
+}
+
+// Err last generation error of paillier keys or PreParams, nil if the latest generations succeeded
+func (p *Pool) Err() error {
+	if err := p.paiKeys.lastErr(); err != nil {
+		return fmt.Errorf("paillier key generation: %v", err)
+	}
+	if err := p.preParams.lastErr(); err != nil {
+		return fmt.Errorf("PreParams generation: %v", err)
+	}
+	return nil
+}
+
+// Close stop background generation, stored items are kept for the next pool
+func (p *Pool) Close() {
+	p.cancel()
+	p.wg.Wait()
+}
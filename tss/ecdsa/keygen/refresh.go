@@ -0,0 +1,190 @@
+package keygen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/paillier"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/key/reshare"
+)
+
+// EcdsaRefresh refresh key shares and the paillier material of a signing pair in one ceremony
+// after the feldman refresh, P1 rotates its paillier key and re-encrypts the new x1 for P2,
+// P2 acknowledges to P1 and P1 confirms the ceremony to every other device, no device has save data before
+type EcdsaRefresh struct {
+	*reshare.RefreshInfo
+
+	p1, p2    int                  // signing pair, P1 holds the paillier private key
+	paiPriKey *paillier.PrivateKey // new paillier key, P1 only
+	preParams *PreParams
+	p1MsgHash string // P1 and P2, hash of the message sent to P2
+
+	keyData    *tss.KeyStep3Data
+	p2SaveData *P2SaveData
+	done       bool
+}
+
+// EcdsaSaveData new key information, only available when the whole ceremony succeeds
+type EcdsaSaveData struct {
+	KeyData    *tss.KeyStep3Data
+	PaiPriKey  *paillier.PrivateKey // P1 only
+	P2SaveData *P2SaveData          // P2 only
+}
+
+type refreshAck struct {
+	MsgHash string
+}
+
+// NewEcdsaRefresh paiPriKey is optional for P1, taken from pre-generated keys, nil generates a new one
+func NewEcdsaRefresh(refresh *reshare.RefreshInfo, p1, p2 int, paiPriKey *paillier.PrivateKey, preParams *PreParams) *EcdsaRefresh {
+	if refresh == nil || p1 == p2 || p1 <= 0 || p2 <= 0 || p1 > refresh.Total || p2 > refresh.Total {
+		panic(fmt.Errorf("NewEcdsaRefresh params error"))
+	}
+	return &EcdsaRefresh{
+		RefreshInfo: refresh,
+		p1:          p1,
+		p2:          p2,
+		paiPriKey:   paiPriKey,
+		preParams:   preParams,
+	}
+}
+
+// DKGStep3 refresh key shares, P1 returns the encrypted new x1 message for P2
+func (r *EcdsaRefresh) DKGStep3(msgs []*tss.Message) (map[int]*tss.Message, error) {
+	keyData, err := r.RefreshInfo.DKGStep3(msgs)
+	if err != nil {
+		return nil, err
+	}
+	r.keyData = keyData
+
+	out := make(map[int]*tss.Message, 1)
+	switch r.DeviceNumber {
+	case r.p1:
+		if r.paiPriKey == nil {
+			r.paiPriKey, _, err = paillier.NewKeyPair()
+			if err != nil {
+				return nil, err
+			}
+		}
+		msg, err := P1WithCurve(keyData.PublicKey.Curve, keyData.ShareI, r.paiPriKey, r.p1, r.p2, r.preParams, paillier.DefaultProfile)
+		if err != nil {
+			return nil, err
+		}
+		r.p1MsgHash = messageHash(msg)
+		out[r.p2] = msg
+	}
+	// P2 waits for the P1 message, the other devices wait for the P1 confirmation
+	return out, nil
+}
+
+// P2Step P2 verifies the new encrypted x1 and paillier key, returns acknowledgement for P1
+func (r *EcdsaRefresh) P2Step(msg *tss.Message) (*tss.Message, error) {
+	if r.DeviceNumber != r.p2 || r.keyData == nil || r.p2SaveData != nil {
+		return nil, fmt.Errorf("round error")
+	}
+	publicKey, err := curves.NewECPoint(r.keyData.PublicKey.Curve, r.keyData.PublicKey.X, r.keyData.PublicKey.Y)
+	if err != nil {
+		return nil, err
+	}
+	p2SaveData, err := P2(r.keyData.ShareI, publicKey, msg, r.p1, r.p2)
+	if err != nil {
+		return nil, err
+	}
+	msgHash := messageHash(msg)
+	bytes, err := json.Marshal(refreshAck{MsgHash: msgHash})
+	if err != nil {
+		return nil, err
+	}
+	r.p1MsgHash = msgHash
+	r.p2SaveData = p2SaveData
+	return &tss.Message{
+		From: r.p2,
+		To:   r.p1,
+		Data: string(bytes),
+	}, nil
+}
+
+// P1Finish P1 checks P2 accepted the new paillier material, returns the confirmation for every other device
+func (r *EcdsaRefresh) P1Finish(msg *tss.Message) (map[int]*tss.Message, error) {
+	if r.DeviceNumber != r.p1 || r.p1MsgHash == "" || r.done {
+		return nil, fmt.Errorf("round error")
+	}
+	if msg.From != r.p2 || msg.To != r.p1 {
+		return nil, fmt.Errorf("message mismatch")
+	}
+	var ack refreshAck
+	err := json.Unmarshal([]byte(msg.Data), &ack)
+	if err != nil {
+		return nil, err
+	}
+	if ack.MsgHash != r.p1MsgHash {
+		return nil, fmt.Errorf("P2 acknowledgement mismatch")
+	}
+	bytes, err := json.Marshal(refreshAck{MsgHash: r.p1MsgHash})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int]*tss.Message, r.Total-1)
+	for i := 1; i <= r.Total; i++ {
+		if i == r.p1 {
+			continue
+		}
+		out[i] = &tss.Message{
+			From: r.p1,
+			To:   i,
+			Data: string(bytes),
+		}
+	}
+	r.done = true
+	return out, nil
+}
+
+// Confirm every device except P1 receives the P1 confirmation, P2 only after its acknowledgement
+func (r *EcdsaRefresh) Confirm(msg *tss.Message) error {
+	if r.DeviceNumber == r.p1 || r.keyData == nil || r.done {
+		return fmt.Errorf("round error")
+	}
+	if r.DeviceNumber == r.p2 && r.p2SaveData == nil {
+		return fmt.Errorf("round error")
+	}
+	if msg.From != r.p1 || msg.To != r.DeviceNumber {
+		return fmt.Errorf("message mismatch")
+	}
+	var ack refreshAck
+	err := json.Unmarshal([]byte(msg.Data), &ack)
+	if err != nil {
+		return err
+	}
+	if ack.MsgHash == "" || (r.DeviceNumber == r.p2 && ack.MsgHash != r.p1MsgHash) {
+		return fmt.Errorf("P1 confirmation mismatch")
+	}
+	r.done = true
+	return nil
+}
+
+// SaveData new key share, paillier private key for P1 and P2SaveData for P2
+// only available after P1Finish on P1 and Confirm on the other devices,
+// a device that fails before that keeps its old key share and paillier material,
+// P1 keeps its old key share and paillier key until the confirmations are delivered
+func (r *EcdsaRefresh) SaveData() (*EcdsaSaveData, error) {
+	if !r.done {
+		return nil, fmt.Errorf("ecdsa refresh is not finished")
+	}
+	saveData := &EcdsaSaveData{KeyData: r.keyData}
+	switch r.DeviceNumber {
+	case r.p1:
+		saveData.PaiPriKey = r.paiPriKey
+	case r.p2:
+		saveData.P2SaveData = r.p2SaveData
+	}
+	return saveData, nil
+}
+
+func messageHash(msg *tss.Message) string {
+	hash := sha256.Sum256([]byte(msg.Data))
+	return hex.EncodeToString(hash[:])
+}
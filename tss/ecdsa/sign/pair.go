@@ -0,0 +1,40 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/okx/threshold-lib/tss/ecdsa/keygen"
+)
+
+// NewPairSign 2-party signature for the pair (i, j), the lower id is P1
+// returns P1Context or P2Context for this device
+func NewPairSign(pairData *keygen.PairData, i, j int, publicKey *ecdsa.PublicKey, message string) (*P1Context, *P2Context, error) {
+	if pairData == nil || publicKey == nil {
+		return nil, nil, fmt.Errorf("NewPairSign params error")
+	}
+	isP1, err := pairData.IsP1(i, j)
+	if err != nil {
+		return nil, nil, err
+	}
+	peer := i
+	if pairData.Id == i {
+		peer = j
+	}
+	if isP1 {
+		p1 := NewP1(publicKey, message, pairData.PaiPriKey)
+		if p1 == nil {
+			return nil, nil, fmt.Errorf("NewPairSign message error")
+		}
+		return p1, nil, nil
+	}
+	p2SaveData := pairData.P2SaveData[peer]
+	if p2SaveData.From != peer || p2SaveData.To != pairData.Id {
+		return nil, nil, fmt.Errorf("P2SaveData mismatch for pair (%d, %d)", i, j)
+	}
+	p2 := NewP2(p2SaveData.X2, p2SaveData.E_x1, publicKey, p2SaveData.PaiPubKey, message)
+	if p2 == nil {
+		return nil, nil, fmt.Errorf("NewPairSign message error")
+	}
+	return nil, p2, nil
+}
@@ -2,8 +2,11 @@ package sign
 
 import (
 	"crypto/ecdsa"
+	"crypto/elliptic"
+	cryptorand "crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"math/big"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v2"
@@ -15,6 +18,7 @@ import (
 )
 
 var (
+	// curve default curve of CalculateM, P1 and P2 sign on the curve of the public key
 	curve = secp256k1.S256()
 )
 
@@ -23,17 +27,26 @@ type P1Context struct {
 
 	publicKey *ecdsa.PublicKey
 	paiPriKey *paillier.PrivateKey
+	curve     elliptic.Curve
 
 	k1      *big.Int
 	message string
 	R2      *curves.ECPoint // k2*G
 	cmtD    *commitment.Witness
+
+	// hedged nonce, k1 is random if nonceKey is nil
+	nonceKey  *big.Int
+	nonceId   []byte
+	nonceRand io.Reader
 }
 
 // NewP1 2-party signature, P1 init
 func NewP1(publicKey *ecdsa.PublicKey, message string, paiPriKey *paillier.PrivateKey) *P1Context {
 	msg, err := hex.DecodeString(message)
-	if err != nil {
+	if err != nil || publicKey == nil || publicKey.Curve == nil {
+		return nil
+	}
+	if _, err = messageToInt(publicKey, msg); err != nil {
 		return nil
 	}
 	data := new(big.Int).SetBytes(msg)
@@ -41,6 +54,7 @@ func NewP1(publicKey *ecdsa.PublicKey, message string, paiPriKey *paillier.Priva
 
 	p1Context := &P1Context{
 		publicKey: publicKey,
+		curve:     publicKey.Curve,
 		message:   message,
 		paiPriKey: paiPriKey,
 		sessionID: sessionId,
@@ -48,19 +62,63 @@ func NewP1(publicKey *ecdsa.PublicKey, message string, paiPriKey *paillier.Priva
 	return p1Context
 }
 
+// NewP1WithOffset 2-party signature with bip32 child key, P1 init
+// publicKey is the root public key, offset is TssKey.PrivateKeyOffset()
+func NewP1WithOffset(publicKey *ecdsa.PublicKey, offset *big.Int, message string, paiPriKey *paillier.PrivateKey) *P1Context {
+	childPubKey, err := childPublicKey(publicKey, offset)
+	if err != nil {
+		return nil
+	}
+	return NewP1(childPubKey, message, paiPriKey)
+}
+
+// SetEpoch key share epoch, bound into the session id, P2 must use the same epoch
+func (p1 *P1Context) SetEpoch(epoch int) *P1Context {
+	p1.sessionID = crypto.SHA256Int(p1.sessionID, big.NewInt(int64(epoch)))
+	return p1
+}
+
+// SetHedgedNonce derive k1 from the share, message, session id and fresh randomness by HMAC-SHA512
+// k1 is fixed before P2 answers, sessionId must be unique per signing session, also when a failed session is retried,
+// a reused id is refused by crypto.ClaimNonceId
+func (p1 *P1Context) SetHedgedNonce(share *big.Int, sessionId []byte) (*P1Context, error) {
+	if share == nil || len(sessionId) == 0 {
+		return nil, fmt.Errorf("SetHedgedNonce share or session id is empty")
+	}
+	err := crypto.ClaimNonceId(share, "ecdsa P1", sessionId)
+	if err != nil {
+		return nil, err
+	}
+	p1.nonceKey = share
+	p1.nonceId = sessionId
+	return p1, nil
+}
+
 func (p1 *P1Context) Step1() (*commitment.Commitment, error) {
 	if BanSignList.Has(hex.EncodeToString(p1.publicKey.X.Bytes())) {
 		return nil, fmt.Errorf("ecdsa sign forbidden, publicKey " + hex.EncodeToString(p1.publicKey.X.Bytes()))
 	}
 	// random generate k1, k=k1*k2
-	p1.k1 = crypto.RandomNum(curve.N)
-	R1 := curves.ScalarToPoint(curve, p1.k1)
+	k1, err := newNonce(p1.curve.Params().N, p1.nonceKey, p1.nonceRand, []byte("P1"), p1.sessionID.Bytes(), []byte(p1.message), p1.nonceId)
+	if err != nil {
+		return nil, err
+	}
+	p1.k1 = k1
+	R1 := curves.ScalarToPoint(p1.curve, p1.k1)
 	cmt := commitment.NewCommitment(p1.sessionID, R1.X, R1.Y)
 	p1.cmtD = &cmt.Msg
 	return &cmt.C, nil
 }
 
 func (p1 *P1Context) Step2(p2Proof *schnorr.Proof, R2 *curves.ECPoint) (*schnorr.Proof, *commitment.Witness, error) {
+	if R2 == nil || R2.X == nil || R2.Y == nil {
+		return nil, nil, fmt.Errorf("R2 is nil")
+	}
+	// R2 must be on the signing curve, refuse points of another curve or invalid-curve points
+	R2, err := curves.NewECPoint(p1.curve, R2.X, R2.Y)
+	if err != nil {
+		return nil, nil, fmt.Errorf("R2 is not on the signing curve")
+	}
 	// zk schnorr verify k2
 	verify := schnorr.VerifyWithId(p1.sessionID, p2Proof, R2)
 	if !verify {
@@ -68,7 +126,7 @@ func (p1 *P1Context) Step2(p2Proof *schnorr.Proof, R2 *curves.ECPoint) (*schnorr
 	}
 	p1.R2 = R2
 	// zk schnorr prove k1
-	R1 := curves.ScalarToPoint(curve, p1.k1)
+	R1 := curves.ScalarToPoint(p1.curve, p1.k1)
 	proof, err := schnorr.ProveWithId(p1.sessionID, p1.k1, R1)
 	if err != nil {
 		return nil, nil, err
@@ -77,10 +135,15 @@ func (p1 *P1Context) Step2(p2Proof *schnorr.Proof, R2 *curves.ECPoint) (*schnorr
 }
 
 func (p1 *P1Context) Step3(E_k2_h_xr *big.Int) (*big.Int, *big.Int, error) {
-	q := curve.N
+	q := p1.curve.Params().N
 	// R = k1*k2*G, k = k1*k2
-	Rx, _ := curve.ScalarMult(p1.R2.X, p1.R2.Y, p1.k1.Bytes())
+	Rx, _ := p1.curve.ScalarMult(p1.R2.X, p1.R2.Y, p1.k1.Bytes())
 	r := new(big.Int).Mod(Rx, q)
+	// ciphertext from P2, check before decrypting
+	err := p1.paiPriKey.ValidateCiphertext(E_k2_h_xr)
+	if err != nil {
+		return nil, nil, err
+	}
 	// paillier Decrypt (h+xr)/k2
 	k2_h_xr, err := p1.paiPriKey.Decrypt(E_k2_h_xr)
 	if err != nil {
@@ -97,12 +160,18 @@ func (p1 *P1Context) Step3(E_k2_h_xr *big.Int) (*big.Int, *big.Int, error) {
 	if s.Sign() == 0 {
 		return nil, nil, fmt.Errorf("calculated S is zero")
 	}
+	if isStark(p1.publicKey) {
+		s, err = starkSignature(q, r, s)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 	message, err := hex.DecodeString(p1.message)
 	if err != nil {
 		return nil, nil, err
 	}
 	// check ecdsa signature
-	ok := ecdsa.Verify(p1.publicKey, message, r, s)
+	ok := verifySignature(p1.publicKey, message, r, s)
 	if !ok {
 		// IMPORTANT: If Verify fails, actively disallow signing to prevent attacks described in CVE-2023-33242
 		BanSignList.Add(hex.EncodeToString(p1.publicKey.X.Bytes()))
@@ -110,3 +179,14 @@ func (p1 *P1Context) Step3(E_k2_h_xr *big.Int) (*big.Int, *big.Int, error) {
 	}
 	return r, s, nil
 }
+
+// newNonce random nonce below the curve order q, or hedged nonce if secret is set
+func newNonce(q, secret *big.Int, rand io.Reader, inputs ...[]byte) (*big.Int, error) {
+	if secret == nil {
+		return crypto.RandomNum(q), nil
+	}
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	return crypto.HedgedNonce(secret, q, rand, inputs...)
+}
@@ -2,8 +2,10 @@ package sign
 
 import (
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"math/big"
 
 	"github.com/okx/threshold-lib/crypto"
@@ -11,6 +13,7 @@ import (
 	"github.com/okx/threshold-lib/crypto/curves"
 	"github.com/okx/threshold-lib/crypto/paillier"
 	"github.com/okx/threshold-lib/crypto/schnorr"
+	"github.com/okx/threshold-lib/crypto/zkp"
 )
 
 type P2Context struct {
@@ -20,15 +23,24 @@ type P2Context struct {
 	E_x1      *big.Int
 	paiPub    *paillier.PublicKey
 	PublicKey *ecdsa.PublicKey
+	curve     elliptic.Curve
 	message   string
 	k2        *big.Int
 	cmtC      *commitment.Commitment
+
+	// hedged nonce, k2 is random unless enabled
+	hedged    bool
+	nonceId   []byte
+	nonceRand io.Reader
 }
 
 // NewP1 2-party signature, P2 init
 func NewP2(bobPri, E_x1 *big.Int, publicKey *ecdsa.PublicKey, paiPub *paillier.PublicKey, message string) *P2Context {
 	msg, err := hex.DecodeString(message)
-	if err != nil {
+	if err != nil || publicKey == nil || publicKey.Curve == nil {
+		return nil
+	}
+	if _, err = messageToInt(publicKey, msg); err != nil {
 		return nil
 	}
 	data := new(big.Int).SetBytes(msg)
@@ -39,18 +51,63 @@ func NewP2(bobPri, E_x1 *big.Int, publicKey *ecdsa.PublicKey, paiPub *paillier.P
 		E_x1:      E_x1,
 		paiPub:    paiPub,
 		PublicKey: publicKey,
+		curve:     publicKey.Curve,
 		message:   message,
 		sessionID: sessionId,
 	}
 	return p2Context
 }
 
+// NewP2WithOffset 2-party signature with bip32 child key, P2 init
+// publicKey is the root public key, offset is TssKey.PrivateKeyOffset(), child x2 = x2 + offset
+func NewP2WithOffset(bobPri, E_x1 *big.Int, publicKey *ecdsa.PublicKey, offset *big.Int, paiPub *paillier.PublicKey, message string) *P2Context {
+	childPubKey, err := childPublicKey(publicKey, offset)
+	if err != nil {
+		return nil
+	}
+	x2 := new(big.Int).Mod(new(big.Int).Add(bobPri, offset), publicKey.Curve.Params().N)
+	return NewP2(x2, E_x1, childPubKey, paiPub, message)
+}
+
+// SetEpoch key share epoch, bound into the session id, P1 must use the same epoch
+func (p2 *P2Context) SetEpoch(epoch int) *P2Context {
+	p2.sessionID = crypto.SHA256Int(p2.sessionID, big.NewInt(int64(epoch)))
+	return p2
+}
+
+// SetHedgedNonce derive k2 from x2, message, session id, P1 commitment and fresh randomness by HMAC-SHA512
+// sessionId must be unique per signing session, a reused id is refused by crypto.ClaimNonceId
+// P1 replaying its commitment gets the same k2 only together with the same R1, so r does not change
+func (p2 *P2Context) SetHedgedNonce(sessionId []byte) (*P2Context, error) {
+	if len(sessionId) == 0 {
+		return nil, fmt.Errorf("SetHedgedNonce session id is empty")
+	}
+	err := crypto.ClaimNonceId(p2.x2, "ecdsa P2", sessionId)
+	if err != nil {
+		return nil, err
+	}
+	p2.hedged = true
+	p2.nonceId = sessionId
+	return p2, nil
+}
+
 func (p2 *P2Context) Step1(cmtC *commitment.Commitment) (*schnorr.Proof, *curves.ECPoint, error) {
+	if cmtC == nil || *cmtC == nil {
+		return nil, nil, fmt.Errorf("p2 Step1 commitment is nil")
+	}
 	p2.cmtC = cmtC
 
 	// random generate k2, k=k1*k2
-	p2.k2 = crypto.RandomNum(curve.N)
-	R2 := curves.ScalarToPoint(curve, p2.k2)
+	var nonceKey *big.Int
+	if p2.hedged {
+		nonceKey = p2.x2
+	}
+	k2, err := newNonce(p2.curve.Params().N, nonceKey, p2.nonceRand, []byte("P2"), p2.sessionID.Bytes(), []byte(p2.message), p2.nonceId, (*cmtC).Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	p2.k2 = k2
+	R2 := curves.ScalarToPoint(p2.curve, p2.k2)
 	proof, err := schnorr.ProveWithId(p2.sessionID, p2.k2, R2)
 	if err != nil {
 		return nil, nil, err
@@ -60,7 +117,7 @@ func (p2 *P2Context) Step1(cmtC *commitment.Commitment) (*schnorr.Proof, *curves
 
 // Step2 paillier encrypt compute, return E[(h+xr)/k2]
 func (p2 *P2Context) Step2(cmtD *commitment.Witness, p1Proof *schnorr.Proof) (*big.Int, error) {
-	q := curve.N
+	q := p2.curve.Params().N
 	// check R1=k1*G commitment
 	commit := commitment.HashCommitment{}
 	commit.C = *p2.cmtC
@@ -72,7 +129,7 @@ func (p2 *P2Context) Step2(cmtD *commitment.Witness, p1Proof *schnorr.Proof) (*b
 	if commitD[0].Cmp(p2.sessionID) != 0 {
 		return nil, fmt.Errorf("p2 Step2 commitment sessionId error")
 	}
-	R1, err := curves.NewECPoint(curve, commitD[1], commitD[2])
+	R1, err := curves.NewECPoint(p2.curve, commitD[1], commitD[2])
 	if err != nil {
 		return nil, err
 	}
@@ -81,7 +138,7 @@ func (p2 *P2Context) Step2(cmtD *commitment.Witness, p1Proof *schnorr.Proof) (*b
 		return nil, fmt.Errorf("schnorr verify fail")
 	}
 	// R = k1*k2*G, k = k1*k2
-	Rx, _ := curve.ScalarMult(R1.X, R1.Y, p2.k2.Bytes())
+	Rx, _ := p2.curve.ScalarMult(R1.X, R1.Y, p2.k2.Bytes())
 	r := new(big.Int).Mod(Rx, q)
 	bytes, err := hex.DecodeString(p2.message)
 	if err != nil {
@@ -89,13 +146,24 @@ func (p2 *P2Context) Step2(cmtD *commitment.Witness, p1Proof *schnorr.Proof) (*b
 	}
 	k2_1 := new(big.Int).ModInverse(p2.k2, q)
 
-	h := CalculateM(bytes)
+	h, err := messageToInt(p2.PublicKey, bytes)
+	if err != nil {
+		return nil, err
+	}
 	h = new(big.Int).Mul(h, k2_1) // h/k2
 
 	rho := crypto.RandomNum(new(big.Int).Mul(q, q))
 	rhoq := new(big.Int).Mul(rho, q)
 	h_rhoq := new(big.Int).Add(h, rhoq) // h/k2 + rho*q
 
+	err = p2.paiPub.Validate()
+	if err != nil {
+		return nil, err
+	}
+	err = zkp.CheckOrder(q, p2.paiPub.N)
+	if err != nil {
+		return nil, err
+	}
 	E_x, err := p2.paiPub.HomoAddPlain(p2.E_x1, p2.x2)
 	if err != nil {
 		return nil, err
@@ -112,8 +180,31 @@ func (p2 *P2Context) Step2(cmtD *commitment.Witness, p1Proof *schnorr.Proof) (*b
 	return E_k2_h_xr, nil
 }
 
+// childPublicKey child publicKey = publicKey + offset*G
+func childPublicKey(publicKey *ecdsa.PublicKey, offset *big.Int) (*ecdsa.PublicKey, error) {
+	if publicKey == nil || publicKey.Curve == nil || offset == nil {
+		return nil, fmt.Errorf("childPublicKey parameters error")
+	}
+	curve := publicKey.Curve
+	point, err := curves.NewECPoint(curve, publicKey.X, publicKey.Y)
+	if err != nil {
+		return nil, err
+	}
+	point, err = point.Add(curves.ScalarToPoint(curve, offset))
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: point.X, Y: point.Y}, nil
+}
+
+// CalculateM hash truncated to the bit length of the secp256k1 order
 func CalculateM(hash []byte) *big.Int {
-	orderBits := curve.N.BitLen()
+	return CalculateMWithCurve(curve, hash)
+}
+
+// CalculateMWithCurve hash truncated to the bit length of the curve order, same as crypto/ecdsa
+func CalculateMWithCurve(curve elliptic.Curve, hash []byte) *big.Int {
+	orderBits := curve.Params().N.BitLen()
 	orderBytes := (orderBits + 7) / 8
 	if len(hash) > orderBytes {
 		hash = hash[:orderBytes]
@@ -2,7 +2,9 @@ package sign
 
 import (
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -12,6 +14,7 @@ import (
 	"github.com/okx/threshold-lib/crypto"
 	"github.com/okx/threshold-lib/crypto/curves"
 	"github.com/okx/threshold-lib/crypto/paillier"
+	"github.com/okx/threshold-lib/crypto/schnorr"
 
 	"testing"
 
@@ -92,7 +95,60 @@ func TestEcdsaSign(t *testing.T) {
 	fmt.Println(r, s)
 }
 
+// TestEcdsaSignWithOffset sign with the bip32 child key offset, shares stay those of the root key
+func TestEcdsaSignWithOffset(t *testing.T) {
+	p1Data, p2Data, _ := KeyGen()
+
+	fmt.Println("=========2/2 keygen==========")
+	preParams := &keygen.PreParams{}
+	err := json.Unmarshal([]byte(preParamsStr), preParams)
+	if err != nil {
+		fmt.Println("preParams Unmarshal error, ", err)
+		return
+	}
+
+	paiPrivate, _, _ := paillier.NewKeyPair(8)
+	p1Dto, _ := keygen.P1(p1Data.ShareI, paiPrivate, p1Data.Id, p2Data.Id, preParams)
+	publicKey, _ := curves.NewECPoint(curve, p2Data.PublicKey.X, p2Data.PublicKey.Y)
+	p2SaveData, err := keygen.P2(p2Data.ShareI, publicKey, p1Dto, p1Data.Id, p2Data.Id)
+	fmt.Println(p2SaveData, err)
+
+	fmt.Println("=========bip32==========")
+	tssKey, err := bip32.NewTssKey(nil, p2Data.PublicKey, p2Data.ChainCode)
+	tssKey, err = tssKey.NewChildKey(996)
+	offset := tssKey.PrivateKeyOffset()
+	pubKey := &ecdsa.PublicKey{Curve: curve, X: p2Data.PublicKey.X, Y: p2Data.PublicKey.Y}
+
+	fmt.Println("=========2/2 sign==========")
+	hash := sha256.New()
+	hash.Write([]byte("hello"))
+	message := hash.Sum(nil)
+
+	p1 := NewP1WithOffset(pubKey, offset, hex.EncodeToString(message), paiPrivate)
+	p2 := NewP2WithOffset(p2SaveData.X2, p2SaveData.E_x1, pubKey, offset, p2SaveData.PaiPubKey, hex.EncodeToString(message))
+
+	commit, _ := p1.Step1()
+	bobProof, R2, _ := p2.Step1(commit)
+
+	proof, cmtD, _ := p1.Step2(bobProof, R2)
+	E_k2_h_xr, _ := p2.Step2(cmtD, proof)
+
+	r, s, err := p1.Step3(E_k2_h_xr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	childPubKey := &ecdsa.PublicKey{Curve: curve, X: tssKey.PublicKey().X, Y: tssKey.PublicKey().Y}
+	if !ecdsa.Verify(childPubKey, message, r, s) {
+		t.Fatal("child key signature verify fail")
+	}
+	fmt.Println(r, s)
+}
+
 func KeyGen() (*tss.KeyStep3Data, *tss.KeyStep3Data, *tss.KeyStep3Data) {
+	return keyGenWithCurve(curve)
+}
+
+func keyGenWithCurve(curve elliptic.Curve) (*tss.KeyStep3Data, *tss.KeyStep3Data, *tss.KeyStep3Data) {
 	setUp1 := dkg.NewSetUp(1, 3, curve)
 	setUp2 := dkg.NewSetUp(2, 3, curve)
 	setUp3 := dkg.NewSetUp(3, 3, curve)
@@ -119,3 +175,263 @@ func KeyGen() (*tss.KeyStep3Data, *tss.KeyStep3Data, *tss.KeyStep3Data) {
 
 	return p1SaveData, p2SaveData, p3SaveData
 }
+
+func TestPairSign(t *testing.T) {
+	p1Data, p2Data, p3Data := KeyGen()
+	preParams := &keygen.PreParams{}
+	err := json.Unmarshal([]byte(preParamsStr), preParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, _ := curves.NewECPoint(curve, p1Data.PublicKey.X, p1Data.PublicKey.Y)
+
+	// the lower id is P1, the highest id needs no paillier key
+	ids := []int{p1Data.Id, p2Data.Id}
+	paiPri1, _, _ := paillier.NewKeyPair(8)
+	pair1 := keygen.NewPairKeyGen(p1Data.Id, ids, p1Data.ShareI, publicKey, paiPri1, preParams)
+	pair2 := keygen.NewPairKeyGen(p2Data.Id, ids, p2Data.ShareI, publicKey, nil, nil)
+	msgs1, err := pair1.Step1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs2, err := pair2.Step1()
+	if err != nil || len(msgs2) != 0 {
+		t.Fatal("the highest id sends no x1", err)
+	}
+	pairData1, err := pair1.Step2(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairData2, err := pair2.Step2([]*tss.Message{msgs1[p2Data.Id]})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubKey := &ecdsa.PublicKey{Curve: curve, X: publicKey.X, Y: publicKey.Y}
+	hash := sha256.Sum256([]byte("hello"))
+	message := hex.EncodeToString(hash[:])
+	pairSign := func(i, j int) {
+		p1a, p2a, err := NewPairSign(pairData1, i, j, pubKey, message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p1b, p2b, err := NewPairSign(pairData2, i, j, pubKey, message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p1a == nil || p2b == nil || p2a != nil || p1b != nil {
+			t.Fatal("the lower id should be P1", i, j)
+		}
+		p1, p2 := p1a, p2b
+
+		commit, _ := p1.Step1()
+		bobProof, R2, _ := p2.Step1(commit)
+		proof, cmtD, _ := p1.Step2(bobProof, R2)
+		E_k2_h_xr, _ := p2.Step2(cmtD, proof)
+		r, s, err := p1.Step3(E_k2_h_xr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ecdsa.Verify(pubKey, hash[:], r, s) {
+			t.Fatal("pair signature verify fail", i, j)
+		}
+	}
+	pairSign(p1Data.Id, p2Data.Id)
+	pairSign(p2Data.Id, p1Data.Id)
+
+	_, _, err = NewPairSign(pairData1, 2, 3, pubKey, message)
+	if err == nil {
+		t.Fatal("device outside the pair should fail")
+	}
+	// x1 of a higher id is refused, it never acts as P1
+	pair2 = keygen.NewPairKeyGen(p2Data.Id, []int{1, 2, 3}, p2Data.ShareI, publicKey, paiPri1, preParams)
+	msg, err := keygen.P1(p3Data.ShareI, paiPri1, p3Data.Id, p2Data.Id, preParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = pair2.Step2([]*tss.Message{msg}); err == nil {
+		t.Fatal("message from a higher id should be refused")
+	}
+}
+
+// stuckReader rng returning zeros
+type stuckReader struct{}
+
+func (stuckReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestHedgedNonce(t *testing.T) {
+	x1 := crypto.RandomNum(curve.N)
+	x2 := crypto.RandomNum(curve.N)
+	_, pub := secp256k1.PrivKeyFromBytes(new(big.Int).Add(x1, x2).Bytes())
+	publicKey := pub.ToECDSA()
+	paiPri, paiPub, _ := paillier.NewKeyPair(8)
+	E_x1, _, _ := paiPub.Encrypt(x1)
+	hash := sha256.Sum256([]byte("hello"))
+	message := hex.EncodeToString(hash[:])
+
+	sign := func(sessionId string) (*big.Int, *big.Int) {
+		p1, err := NewP1(publicKey, message, paiPri).SetHedgedNonce(x1, []byte(sessionId))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p2, err := NewP2(x2, E_x1, publicKey, paiPub, message).SetHedgedNonce([]byte(sessionId))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p1.nonceRand, p2.nonceRand = stuckReader{}, stuckReader{}
+
+		commit, _ := p1.Step1()
+		bobProof, R2, _ := p2.Step1(commit)
+		proof, cmtD, _ := p1.Step2(bobProof, R2)
+		E_k2_h_xr, _ := p2.Step2(cmtD, proof)
+		r, s, err := p1.Step3(E_k2_h_xr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ecdsa.Verify(publicKey, hash[:], r, s) {
+			t.Fatal("hedged nonce signature verify fail")
+		}
+		return p1.k1, p2.k2
+	}
+	k1a, k2a := sign("session-1")
+	k1b, k2b := sign("session-2")
+	if k1a.Cmp(k1b) == 0 || k2a.Cmp(k2b) == 0 {
+		t.Fatal("stuck rng repeated the nonce across sessions")
+	}
+	if _, err := NewP1(publicKey, message, paiPri).SetHedgedNonce(x1, nil); err == nil {
+		t.Fatal("empty session id should be refused")
+	}
+	// a retried session must not reuse the id
+	if _, err := NewP1(publicKey, message, paiPri).SetHedgedNonce(x1, []byte("session-1")); err == nil {
+		t.Fatal("reused session id should be refused")
+	}
+	if _, err := NewP2(x2, E_x1, publicKey, paiPub, message).SetHedgedNonce([]byte("session-1")); err == nil {
+		t.Fatal("reused session id should be refused")
+	}
+	if _, err := NewP2(x2, E_x1, publicKey, paiPub, message).SetHedgedNonce([]byte{}); err == nil {
+		t.Fatal("empty session id should be refused")
+	}
+}
+
+func TestP256Sign(t *testing.T) {
+	p256 := elliptic.P256()
+	p1Data, p2Data, _ := keyGenWithCurve(p256)
+	preParams := &keygen.PreParams{}
+	err := json.Unmarshal([]byte(preParamsStr), preParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paiPrivate, _, _ := paillier.NewKeyPair(8)
+	p1Dto, err := keygen.P1WithCurve(p256, p1Data.ShareI, paiPrivate, p1Data.Id, p2Data.Id, preParams, paillier.DefaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2SaveData, err := keygen.P2(p2Data.ShareI, p2Data.PublicKey, p1Dto, p1Data.Id, p2Data.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// P1 message on secp256k1 is refused for a P-256 key
+	secpDto, _ := keygen.P1(p1Data.ShareI, paiPrivate, p1Data.Id, p2Data.Id, preParams)
+	if _, err := keygen.P2(p2Data.ShareI, p2Data.PublicKey, secpDto, p1Data.Id, p2Data.Id); err == nil {
+		t.Fatal("curve mismatch must be refused")
+	}
+
+	// sha512 is longer than the P-256 order and is truncated like crypto/ecdsa
+	hash := sha512.Sum512([]byte("hello"))
+	message := hash[:]
+	pubKey := &ecdsa.PublicKey{Curve: p256, X: p2Data.PublicKey.X, Y: p2Data.PublicKey.Y}
+	p1 := NewP1(pubKey, hex.EncodeToString(message), paiPrivate)
+	p2 := NewP2(p2SaveData.X2, p2SaveData.E_x1, pubKey, p2SaveData.PaiPubKey, hex.EncodeToString(message))
+
+	commit, _ := p1.Step1()
+	bobProof, R2, _ := p2.Step1(commit)
+	proof, cmtD, _ := p1.Step2(bobProof, R2)
+	E_k2_h_xr, err := p2.Step2(cmtD, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, s, err := p1.Step3(E_k2_h_xr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ecdsa.Verify(pubKey, message, r, s) {
+		t.Fatal("P-256 signature verify fail")
+	}
+
+	// R2 on secp256k1 with a valid proof, sent as it is and relabelled as a P-256 point
+	p1 = NewP1(pubKey, hex.EncodeToString(message), paiPrivate)
+	_, _ = p1.Step1()
+	k := crypto.RandomNum(curve.N)
+	badR2 := curves.ScalarToPoint(curve, k)
+	badProof, _ := schnorr.ProveWithId(p1.sessionID, k, badR2)
+	for _, point := range []*curves.ECPoint{badR2, {Curve: p256, X: badR2.X, Y: badR2.Y}, {Curve: p256}} {
+		if _, _, err := p1.Step2(badProof, point); err == nil {
+			t.Fatal("R2 not on the signing curve must be refused")
+		}
+	}
+	if CalculateMWithCurve(p256, message).Cmp(new(big.Int).SetBytes(message[:32])) != 0 {
+		t.Fatal("hash truncation error")
+	}
+}
+
+func TestStarkSign(t *testing.T) {
+	stark := curves.StarkCurve()
+	// starknet.js / starkware crypto test vector
+	priv, _ := new(big.Int).SetString("3c1e9550e66958296d11b60f8e8e7a7ad990d07fa65d5f7652c4a6c87d4e3cc", 16)
+	point := curves.ScalarToPoint(stark, priv)
+	if point.X.Text(16) != "77a3b314db07c45076d11f62b6f9e748a39790441823307743cf00d6597ea43" {
+		t.Fatal("stark public key error")
+	}
+	msgHash, _ := hex.DecodeString("0397e76d1667c4454bfb83514e120583af836f8e32a516765497823eabe16a3f")
+	r, _ := new(big.Int).SetString("173fd03d8b008ee7432977ac27d1e9d1a1f6c98b1a2f05fa84a21c84c44e882", 16)
+	s, _ := new(big.Int).SetString("4b6d75385aed025aa222f28a0adc6d58db78ff17e51c3f59e259b131cd5a1cc", 16)
+	vectorKey := &ecdsa.PublicKey{Curve: stark, X: point.X, Y: point.Y}
+	if !StarkVerify(vectorKey, msgHash, r, s) {
+		t.Fatal("stark vector verify fail")
+	}
+
+	p1Data, p2Data, _ := keyGenWithCurve(stark)
+	preParams := &keygen.PreParams{}
+	err := json.Unmarshal([]byte(preParamsStr), preParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paiPrivate, _, _ := paillier.NewKeyPair(8)
+	p1Dto, err := keygen.P1WithCurve(stark, p1Data.ShareI, paiPrivate, p1Data.Id, p2Data.Id, preParams, paillier.DefaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2SaveData, err := keygen.P2(p2Data.ShareI, p2Data.PublicKey, p1Dto, p1Data.Id, p2Data.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := &ecdsa.PublicKey{Curve: stark, X: p2Data.PublicKey.X, Y: p2Data.PublicKey.Y}
+
+	// message hash is a field element below 2^251
+	if NewP1(pubKey, "0800000000000000000000000000000000000000000000000000000000000000", paiPrivate) != nil {
+		t.Fatal("stark message out of range must be refused")
+	}
+	message := hex.EncodeToString(msgHash)
+	p1 := NewP1(pubKey, message, paiPrivate)
+	p2 := NewP2(p2SaveData.X2, p2SaveData.E_x1, pubKey, p2SaveData.PaiPubKey, message)
+
+	commit, _ := p1.Step1()
+	bobProof, R2, _ := p2.Step1(commit)
+	proof, cmtD, _ := p1.Step2(bobProof, R2)
+	E_k2_h_xr, err := p2.Step2(cmtD, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, s, err = p1.Step3(E_k2_h_xr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !StarkVerify(pubKey, msgHash, r, s) {
+		t.Fatal("stark signature verify fail")
+	}
+}
@@ -0,0 +1,75 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+)
+
+// starkBound StarkNet message hash, r and w = 1/s are field elements below 2^251
+var starkBound = new(big.Int).Lsh(big.NewInt(1), 251)
+
+func isStark(publicKey *ecdsa.PublicKey) bool {
+	return curves.GetCurveName(publicKey.Curve) == curves.Stark
+}
+
+// messageToInt on the STARK curve the message is the field element hash itself and is not truncated
+func messageToInt(publicKey *ecdsa.PublicKey, message []byte) (*big.Int, error) {
+	if !isStark(publicKey) {
+		return CalculateMWithCurve(publicKey.Curve, message), nil
+	}
+	z := new(big.Int).SetBytes(message)
+	if z.Cmp(starkBound) >= 0 {
+		return nil, fmt.Errorf("stark message hash must be below 2^251")
+	}
+	return z, nil
+}
+
+// starkSignature check r, pick s or q-s so that w = 1/s is below 2^251, the low s is preferred
+// r out of range only happens with probability about 2^-55, sign again with fresh nonces
+func starkSignature(q, r, s *big.Int) (*big.Int, error) {
+	if r.Sign() == 0 || r.Cmp(starkBound) >= 0 {
+		return nil, fmt.Errorf("stark r out of range, sign again")
+	}
+	w := new(big.Int).ModInverse(s, q)
+	if w.Cmp(starkBound) >= 0 {
+		s = new(big.Int).Sub(q, s)
+	}
+	return s, nil
+}
+
+// StarkVerify verify a StarkNet signature, messageHash is the big-endian field element that was signed
+func StarkVerify(publicKey *ecdsa.PublicKey, messageHash []byte, r, s *big.Int) bool {
+	if publicKey == nil || !isStark(publicKey) || r == nil || s == nil {
+		return false
+	}
+	if !publicKey.Curve.IsOnCurve(publicKey.X, publicKey.Y) {
+		return false
+	}
+	q := publicKey.Curve.Params().N
+	z := new(big.Int).SetBytes(messageHash)
+	if z.Cmp(starkBound) >= 0 || r.Sign() <= 0 || r.Cmp(starkBound) >= 0 || s.Sign() <= 0 || s.Cmp(q) >= 0 {
+		return false
+	}
+	w := new(big.Int).ModInverse(s, q)
+	if w.Cmp(starkBound) >= 0 {
+		return false
+	}
+	// x((z*w)*G + (r*w)*Q) == r
+	u1 := new(big.Int).Mod(new(big.Int).Mul(z, w), q)
+	u2 := new(big.Int).Mod(new(big.Int).Mul(r, w), q)
+	x1, y1 := publicKey.Curve.ScalarBaseMult(u1.Bytes())
+	x2, y2 := publicKey.Curve.ScalarMult(publicKey.X, publicKey.Y, u2.Bytes())
+	x, _ := publicKey.Curve.Add(x1, y1, x2, y2)
+	return x.Cmp(r) == 0
+}
+
+// verifySignature ecdsa.Verify, or StarkVerify on the STARK curve
+func verifySignature(publicKey *ecdsa.PublicKey, message []byte, r, s *big.Int) bool {
+	if isStark(publicKey) {
+		return StarkVerify(publicKey, message, r, s)
+	}
+	return ecdsa.Verify(publicKey, message, r, s)
+}
@@ -0,0 +1,32 @@
+package sign
+
+import (
+	stded25519 "crypto/ed25519"
+	"encoding/hex"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+)
+
+// SetSharePubKeyMap ShareI*G map from KeyStep3Data, required to verify partial signatures
+func (ed25519 *Ed25519Sign) SetSharePubKeyMap(sharePubKeyMap map[int]*curves.ECPoint) *Ed25519Sign {
+	ed25519.Signer.SetSharePubKeyMap(sharePubKeyMap)
+	return ed25519
+}
+
+// encode R || s, s in 32 bytes little endian
+func (ed25519 *Ed25519Sign) encode(R *curves.ECPoint, s *big.Int) ([]byte, error) {
+	signature := make([]byte, 0, stded25519.SignatureSize)
+	signature = append(signature, edwards.NewPublicKey(R.X, R.Y).Serialize()...)
+	signature = append(signature, bigIntToEncodedBytes(s)[:]...)
+	return signature, nil
+}
+
+func (ed25519 *Ed25519Sign) verify(signature []byte) bool {
+	bytes, err := hex.DecodeString(ed25519.Message())
+	if err != nil {
+		return false
+	}
+	return Verify(ed25519.PublicKey, bytes, signature, ed25519.variant, ed25519.context)
+}
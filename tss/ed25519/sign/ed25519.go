@@ -1,51 +1,105 @@
 package sign
 
 import (
+	"fmt"
+	"io"
 	"math/big"
 
 	"github.com/decred/dcrd/dcrec/edwards/v2"
-	"github.com/okx/threshold-lib/crypto/commitment"
-	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss/schnorrsign"
 )
 
 var (
 	curve = edwards.Edwards()
 )
 
+// Ed25519Sign three rounds of schnorrsign.Signer with the RFC 8032 challenge, Aggregate returns R || s
 type Ed25519Sign struct {
-	DeviceNumber int
-	Threshold    int
-	partList     []int // participating signature number, usually 2
-	wi           *big.Int
-	PublicKey    *edwards.PublicKey
-	RoundNumber  int
-	ki           *big.Int
-	message      string
-
-	cmtD          commitment.Witness
-	CommitmentMap map[int]commitment.Commitment
+	*schnorrsign.Signer
+	PublicKey *edwards.PublicKey
+	R         *edwards.PublicKey // R = sum(Ri), available after SignStep3
+
+	// RFC 8032 variant, pure Ed25519 by default
+	variant Variant
+	context []byte
+
+	// hedged nonce, ki is random unless enabled
+	hedged    bool
+	nonceId   []byte
+	nonceRand io.Reader
 }
 
+type (
+	Step1Data = schnorrsign.Step1Data
+	Step2Data = schnorrsign.Step2Data
+)
+
 // NewEd25519Sign
 func NewEd25519Sign(deviceNumber, threshold int, partList []int, ShareI *big.Int, PublicKey *edwards.PublicKey, message string) *Ed25519Sign {
-	if len(partList) != threshold {
+	ed25519 := &Ed25519Sign{PublicKey: PublicKey}
+	ed25519.Signer = schnorrsign.NewSigner(curve, deviceNumber, threshold, partList, ShareI, message, schnorrsign.Scheme{
+		Nonce:     ed25519.nonce,
+		Challenge: ed25519.challenge,
+		Encode:    ed25519.encode,
+		Verify:    ed25519.verify,
+	})
+	if ed25519.Signer == nil {
 		return nil
 	}
-	xList := make([]*big.Int, len(partList))
-	for i, x := range partList {
-		xList[i] = big.NewInt(int64(x))
+	return ed25519
+}
+
+// SetEpoch key share epoch, KeyStep3Data.Epoch, refuse to sign with shares of different epochs
+func (ed25519 *Ed25519Sign) SetEpoch(epoch int) *Ed25519Sign {
+	ed25519.Signer.SetEpoch(epoch)
+	return ed25519
+}
+
+// SetHedgedNonce derive ki from wi, message, participants, session id and fresh randomness by HMAC-SHA512
+// ki is committed before the peers' Rj are known, so it cannot depend on them, sessionId must be unique per
+// signing session, also when a failed session is retried, a reused id is refused by crypto.ClaimNonceId
+func (ed25519 *Ed25519Sign) SetHedgedNonce(sessionId []byte) (*Ed25519Sign, error) {
+	if len(sessionId) == 0 {
+		return nil, fmt.Errorf("SetHedgedNonce session id is empty")
+	}
+	err := ed25519.ClaimNonceId("ed25519", sessionId)
+	if err != nil {
+		return nil, err
 	}
-	// lagrangian interpolation wi
-	wi := vss.CalLagrangian(curve, big.NewInt(int64(deviceNumber)), ShareI, xList)
+	ed25519.hedged = true
+	ed25519.nonceId = sessionId
+	return ed25519, nil
+}
 
-	ed25519 := &Ed25519Sign{
-		DeviceNumber: deviceNumber,
-		Threshold:    threshold,
-		wi:           wi,
-		partList:     partList,
-		PublicKey:    PublicKey,
-		message:      message,
-		RoundNumber:  1,
+// NewEd25519SignWithOffset sign with bip32 child key, PublicKey is the root public key, offset is TssKey.PrivateKeyOffset()
+// the offset is added only once, by the smallest device number in partList
+func NewEd25519SignWithOffset(deviceNumber, threshold int, partList []int, ShareI *big.Int, PublicKey *edwards.PublicKey, offset *big.Int, message string) *Ed25519Sign {
+	ed25519 := NewEd25519Sign(deviceNumber, threshold, partList, ShareI, PublicKey, message)
+	if ed25519 == nil {
+		return nil
+	}
+	childPubKey, err := childPublicKey(PublicKey, offset)
+	if err != nil {
+		return nil
 	}
+	ed25519.AddOffset(offset)
+	ed25519.PublicKey = childPubKey
 	return ed25519
 }
+
+// childPublicKey child publicKey = publicKey + offset*G
+func childPublicKey(publicKey *edwards.PublicKey, offset *big.Int) (*edwards.PublicKey, error) {
+	if publicKey == nil || offset == nil {
+		return nil, fmt.Errorf("childPublicKey parameters error")
+	}
+	point, err := curves.NewECPoint(curve, publicKey.X, publicKey.Y)
+	if err != nil {
+		return nil, err
+	}
+	point, err = point.Add(curves.ScalarToPoint(curve, offset))
+	if err != nil {
+		return nil, err
+	}
+	return edwards.NewPublicKey(point.X, point.Y), nil
+}
@@ -1,12 +1,16 @@
 package sign
 
 import (
+	"bytes"
+	stded25519 "crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
 	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/key/bip32"
 	"github.com/okx/threshold-lib/tss/key/dkg"
 	"math/big"
 	"testing"
@@ -27,6 +31,58 @@ func TestEd25519(t *testing.T) {
 	}
 }
 
+func TestEd25519WithOffset(t *testing.T) {
+	p1Data, _, p3Data := keyGen(curve)
+
+	hash := sha256.New()
+	hash.Write([]byte("hello"))
+	message := hash.Sum(nil)
+	publicKey := edwards.NewPublicKey(p1Data.PublicKey.X, p1Data.PublicKey.Y)
+
+	tssKey, _ := bip32.NewTssKey(nil, p1Data.PublicKey, p1Data.ChainCode)
+	tssKey, _ = tssKey.NewChildKey(996)
+	offset := tssKey.PrivateKeyOffset()
+
+	partList := []int{1, 3}
+	p1 := NewEd25519SignWithOffset(1, 2, partList, p1Data.ShareI, publicKey, offset, hex.EncodeToString(message))
+	p3 := NewEd25519SignWithOffset(3, 2, partList, p3Data.ShareI, publicKey, offset, hex.EncodeToString(message))
+
+	p1Step1, _ := p1.SignStep1()
+	p3Step1, _ := p3.SignStep1()
+
+	p1Step2, _ := p1.SignStep2([]*tss.Message{p3Step1[1]})
+	p3Step2, _ := p3.SignStep2([]*tss.Message{p1Step1[3]})
+
+	si_1, r, _ := p1.SignStep3([]*tss.Message{p3Step2[1]})
+	si_3, r, _ := p3.SignStep3([]*tss.Message{p1Step2[3]})
+
+	s := new(big.Int).Add(si_1, si_3)
+	signature := edwards.NewSignature(r, s)
+	childPubKey := edwards.NewPublicKey(tssKey.PublicKey().X, tssKey.PublicKey().Y)
+	if !signature.Verify(message, childPubKey) {
+		t.Fatal("child key signature verify fail")
+	}
+}
+
+func TestEd25519Epoch(t *testing.T) {
+	p1Data, p2Data, _ := keyGen(curve)
+	publicKey := edwards.NewPublicKey(p1Data.PublicKey.X, p1Data.PublicKey.Y)
+	message := hex.EncodeToString([]byte("hello"))
+
+	partList := []int{1, 2}
+	p1 := NewEd25519Sign(1, 2, partList, p1Data.ShareI, publicKey, message).SetEpoch(1)
+	p2 := NewEd25519Sign(2, 2, partList, p2Data.ShareI, publicKey, message).SetEpoch(0)
+
+	p1Step1, _ := p1.SignStep1()
+	p2Step1, _ := p2.SignStep1()
+	if _, err := p1.SignStep2([]*tss.Message{p2Step1[1]}); err == nil {
+		t.Fatal("mixing epochs should fail")
+	}
+	if _, err := p2.SignStep2([]*tss.Message{p1Step1[2]}); err == nil {
+		t.Fatal("mixing epochs should fail")
+	}
+}
+
 func sign_p1_p2(p1Data, p2Data *tss.KeyStep3Data, publicKey *edwards.PublicKey, message []byte) {
 	fmt.Println("=========sign_p1_p2========")
 	partList := []int{1, 2}
@@ -123,3 +179,308 @@ func keyGen(curve elliptic.Curve) (*tss.KeyStep3Data, *tss.KeyStep3Data, *tss.Ke
 
 	return p1SaveData, p2SaveData, p3SaveData
 }
+
+// stuckReader rng returning zeros
+type stuckReader struct{}
+
+func (stuckReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestEd25519HedgedNonce(t *testing.T) {
+	p1Data, p2Data, _ := keyGen(curve)
+	publicKey := edwards.NewPublicKey(p1Data.PublicKey.X, p1Data.PublicKey.Y)
+	hash := sha256.Sum256([]byte("hello"))
+	message := hex.EncodeToString(hash[:])
+	partList := []int{1, 2}
+
+	sign := func(sessionId string) *big.Int {
+		p1, err := NewEd25519Sign(1, 2, partList, p1Data.ShareI, publicKey, message).SetHedgedNonce([]byte(sessionId))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p2, err := NewEd25519Sign(2, 2, partList, p2Data.ShareI, publicKey, message).SetHedgedNonce([]byte(sessionId))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p1.nonceRand, p2.nonceRand = stuckReader{}, stuckReader{}
+
+		p1Step1, _ := p1.SignStep1()
+		p2Step1, _ := p2.SignStep1()
+		p1Step2, _ := p1.SignStep2([]*tss.Message{p2Step1[1]})
+		p2Step2, _ := p2.SignStep2([]*tss.Message{p1Step1[2]})
+		si_1, r, _ := p1.SignStep3([]*tss.Message{p2Step2[1]})
+		si_2, r, _ := p2.SignStep3([]*tss.Message{p1Step2[2]})
+
+		signature := edwards.NewSignature(r, new(big.Int).Add(si_1, si_2))
+		if !signature.Verify(hash[:], publicKey) {
+			t.Fatal("hedged nonce signature verify fail")
+		}
+		return p1.RiMap()[1].X
+	}
+	k1 := sign("session-1")
+	k2 := sign("session-2")
+	if k1.Cmp(k2) == 0 {
+		t.Fatal("stuck rng repeated the nonce across sessions")
+	}
+	if _, err := NewEd25519Sign(1, 2, partList, p1Data.ShareI, publicKey, message).SetHedgedNonce(nil); err == nil {
+		t.Fatal("empty session id should be refused")
+	}
+	if _, err := NewEd25519Sign(1, 2, partList, p1Data.ShareI, publicKey, message).SetHedgedNonce([]byte("session-1")); err == nil {
+		t.Fatal("reused session id should be refused")
+	}
+}
+
+func TestEd25519Aggregate(t *testing.T) {
+	p1Data, _, p3Data := keyGen(curve)
+	publicKey := edwards.NewPublicKey(p1Data.PublicKey.X, p1Data.PublicKey.Y)
+	message := []byte("hello")
+
+	tssKey, _ := bip32.NewTssKey(nil, p1Data.PublicKey, p1Data.ChainCode)
+	tssKey, _ = tssKey.NewChildKey(996)
+	offset := tssKey.PrivateKeyOffset()
+
+	partList := []int{1, 3}
+	p1 := NewEd25519SignWithOffset(1, 2, partList, p1Data.ShareI, publicKey, offset, hex.EncodeToString(message)).SetSharePubKeyMap(p1Data.SharePubKeyMap)
+	p3 := NewEd25519SignWithOffset(3, 2, partList, p3Data.ShareI, publicKey, offset, hex.EncodeToString(message)).SetSharePubKeyMap(p3Data.SharePubKeyMap)
+
+	p1Step1, _ := p1.SignStep1()
+	p3Step1, _ := p3.SignStep1()
+	p1Step2, _ := p1.SignStep2([]*tss.Message{p3Step1[1]})
+	p3Step2, _ := p3.SignStep2([]*tss.Message{p1Step1[3]})
+	si_1, r, _ := p1.SignStep3([]*tss.Message{p3Step2[1]})
+	si_3, _, _ := p3.SignStep3([]*tss.Message{p1Step2[3]})
+
+	partials := map[int]*big.Int{1: si_1, 3: si_3}
+	signature, err := p3.Aggregate(partials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	childPubKey := edwards.NewPublicKey(tssKey.PublicKey().X, tssKey.PublicKey().Y)
+	if len(signature) != stded25519.SignatureSize || !stded25519.Verify(childPubKey.Serialize(), message, signature) {
+		t.Fatal("aggregated signature verify fail")
+	}
+
+	if !p1.VerifyPartial(1, si_1, r, hex.EncodeToString(message)) || !p1.VerifyPartial(3, si_3, r, hex.EncodeToString(message)) {
+		t.Fatal("partial signature verify fail")
+	}
+	if len(p1.RiMap()) != 2 || !p1.RiMap()[3].Equals(p3.RiMap()[3]) {
+		t.Fatal("Ri mismatch")
+	}
+
+	partials[1] = new(big.Int).Add(si_1, big.NewInt(1))
+	_, err = p3.Aggregate(partials)
+	invalidErr, ok := err.(*tss.InvalidPartialError)
+	if !ok || len(invalidErr.Ids) != 1 || invalidErr.Ids[0] != 1 {
+		t.Fatal("invalid partial signature of device 1 should be identified", err)
+	}
+	if p3.VerifyPartial(1, partials[1], r, hex.EncodeToString(message)) {
+		t.Fatal("invalid partial signature accepted")
+	}
+}
+
+// leBytesToBigInt RFC 9591 serialized scalar
+func leBytesToBigInt(s string) *big.Int {
+	return encodedBytesToBigInt(copyBytes(hexBytes(s)))
+}
+
+// TestFrostVector RFC 9591 appendix E.1, FROST(Ed25519, SHA-512)
+func TestFrostVector(t *testing.T) {
+	groupSecret := leBytesToBigInt("7b1c33d3f5291d85de664833beb1ad469f7fb6025a0ec78b3a790c6e13a98304")
+	groupPub := curves.ScalarToPoint(curve, groupSecret)
+	publicKey := edwards.NewPublicKey(groupPub.X, groupPub.Y)
+	if hex.EncodeToString(publicKey.Serialize()) != "15d21ccd7ee42959562fc8aa63224c8851fb3ec85a3faf66040d380fb9738673" {
+		t.Fatal("group public key mismatch")
+	}
+	share1 := leBytesToBigInt("929dcc590407aae7d388761cddb0c0db6f5627aea8e217f4a033f2ec83d93509")
+	share3 := leBytesToBigInt("d3cb090a075eb154e82fdb4b3cb507f110040905468bb9c46da8bdea643a9a02")
+	message := "74657374"
+
+	p1 := NewFrostSign(1, 2, share1, publicKey)
+	p1.rand = bytes.NewReader(append(
+		hexBytes("0fd2e39e111cdc266f6c0f4d0fd45c947761f1f5d3cb583dfcb9bbaf8d4c9fec"),
+		hexBytes("69cd85f631d5f7f2721ed5e40519b1366f340a87c2f6856363dbdcda348a7501")...))
+	p3 := NewFrostSign(3, 2, share3, publicKey)
+	p3.rand = bytes.NewReader(append(
+		hexBytes("86d64a260059e495d0fb4fcc17ea3da7452391baa494d4b00321098ed2a0062f"),
+		hexBytes("13e6b25afb2eba51716a9a7d44130c0dbae0004a9ef8d7b5550c8a0e07c61775")...))
+
+	cmt1, err := p1.Preprocess(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt3, err := p3.Preprocess(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(serializeElement(cmt1[0].Hiding)) != "b5aa8ab305882a6fc69cbee9327e5a45e54c08af61ae77cb8207be3d2ce13de3" ||
+		hex.EncodeToString(serializeElement(cmt1[0].Binding)) != "67e98ab55aa310c3120418e5050c9cf76cf387cb20ac9e4b6fdb6f82a469f932" ||
+		hex.EncodeToString(serializeElement(cmt3[0].Hiding)) != "cfbdb165bd8aad6eb79deb8d287bcc0ab6658ae57fdcc98ed12c0669e90aec91" ||
+		hex.EncodeToString(serializeElement(cmt3[0].Binding)) != "7487bc41a6e712eea2f2af24681b58b1cf1da278ea11fe4e8b78398965f13552" {
+		t.Fatal("nonce commitment mismatch")
+	}
+
+	commitments := []*FrostCommitment{cmt3[0], cmt1[0]}
+	z1, err := p1.Sign(message, commitments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z3, err := p3.Sign(message, commitments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(bigIntToEncodedBytes(z1)[:]) != "001719ab5a53ee1a12095cd088fd149702c0720ce5fd2f29dbecf24b7281b603" ||
+		hex.EncodeToString(bigIntToEncodedBytes(z3)[:]) != "bd86125de990acc5e1f13781d8e32c03a9bbd4c53539bbc106058bfd14326007" {
+		t.Fatal("signature share mismatch")
+	}
+	sharePubKeyMap := map[int]*curves.ECPoint{1: curves.ScalarToPoint(curve, share1), 3: curves.ScalarToPoint(curve, share3)}
+	signature, err := FrostAggregate(publicKey, message, commitments, map[int]*big.Int{1: z1, 3: z3}, sharePubKeyMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(signature) != "36282629c383bb820a88b71cae937d41f2f2adfcc3d02e55507e2fb9e2dd3cbebd9d2b0844e49ae0f3fa935161e1419aab7b47d21a37ebeae1f17d4987b3160b" {
+		t.Fatal("signature mismatch")
+	}
+
+	_, err = p1.Sign(message, commitments)
+	if err == nil {
+		t.Fatal("nonce must not be used twice")
+	}
+}
+
+func TestFrost(t *testing.T) {
+	p1Data, p2Data, p3Data := keyGen(curve)
+	publicKey := edwards.NewPublicKey(p1Data.PublicKey.X, p1Data.PublicKey.Y)
+	message := hex.EncodeToString([]byte("hello"))
+
+	signers := map[int]*FrostSign{
+		1: NewFrostSign(1, 2, p1Data.ShareI, publicKey),
+		2: NewFrostSign(2, 2, p2Data.ShareI, publicKey),
+		3: NewFrostSign(3, 2, p3Data.ShareI, publicKey),
+	}
+	// preprocessing, one commitment per signing session
+	preprocessed := make(map[int][]*FrostCommitment)
+	for id, signer := range signers {
+		commitments, err := signer.Preprocess(4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		preprocessed[id] = commitments
+	}
+
+	for session, partList := range [][]int{{1, 2}, {1, 3}, {2, 3}, {1, 2, 3}} {
+		var commitments []*FrostCommitment
+		for _, id := range partList {
+			commitments = append(commitments, preprocessed[id][session])
+		}
+		shares := make(map[int]*big.Int)
+		for _, id := range partList {
+			zi, err := signers[id].Sign(message, commitments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			shares[id] = zi
+		}
+		signature, err := FrostAggregate(publicKey, message, commitments, shares, p1Data.SharePubKeyMap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !stded25519.Verify(publicKey.Serialize(), []byte("hello"), signature) {
+			t.Fatal("frost signature verify fail", partList)
+		}
+
+		shares[partList[0]] = new(big.Int).Add(shares[partList[0]], big.NewInt(1))
+		_, err = FrostAggregate(publicKey, message, commitments, shares, p1Data.SharePubKeyMap)
+		invalidErr, ok := err.(*tss.InvalidPartialError)
+		if !ok || len(invalidErr.Ids) != 1 || invalidErr.Ids[0] != partList[0] {
+			t.Fatal("invalid signature share should be identified", err)
+		}
+		_, err = FrostAggregate(publicKey, message, commitments, shares, nil)
+		if err == nil {
+			t.Fatal("aggregate without share public keys should fail")
+		}
+	}
+
+	_, err := signers[1].Sign(message, []*FrostCommitment{preprocessed[1][0]})
+	if err == nil {
+		t.Fatal("signers less than threshold")
+	}
+}
+
+func hexBytes(s string) []byte {
+	b, _ := hex.DecodeString(s)
+	return b
+}
+
+// TestEd25519VariantVectors RFC 8032 section 7.2 and 7.3
+func TestEd25519VariantVectors(t *testing.T) {
+	vectors := []struct {
+		publicKey, message, context, signature string
+		variant                                Variant
+	}{
+		{
+			"dfc9425e4f968f7f0c29f0259cf5f9aed6851c2bb4ad8bfb860cfee0ab248292",
+			"f726936d19c800494e3fdaff20b276a8", "666f6f",
+			"55a4cc2f70a54e04288c5f4cd1e45a7bb520b36292911876cada7323198dd87a8b36950b95130022907a7fb7c4e9b2d5f6cca685a587b4b21f4b888e4e7edb0d",
+			VariantCtx,
+		},
+		{
+			"ec172b93ad5e563bf4932c70e1245034c35467ef2efd4d64ebf819683467e2bf",
+			"616263", "",
+			"98a70222f0b8121aa9d30f813d683f809e462b469c7ff87639499bb94e6dae4131f85042463c2a355a2003d062adf5aaa10b8c61e636062aaad11c2a26083406",
+			VariantPh,
+		},
+	}
+	for i, v := range vectors {
+		publicKey, err := edwards.ParsePubKey(hexBytes(v.publicKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+		message, context, signature := hexBytes(v.message), hexBytes(v.context), hexBytes(v.signature)
+		if !Verify(publicKey, message, signature, v.variant, context) {
+			t.Fatal("variant vector verify fail", i)
+		}
+		if Verify(publicKey, message, signature, VariantPure, nil) || Verify(publicKey, message, signature, v.variant, []byte("bar")) {
+			t.Fatal("signature must be bound to the variant and context", i)
+		}
+	}
+}
+
+func TestEd25519Variant(t *testing.T) {
+	p1Data, p2Data, _ := keyGen(curve)
+	publicKey := edwards.NewPublicKey(p1Data.PublicKey.X, p1Data.PublicKey.Y)
+	message := []byte("hello")
+	partList := []int{1, 2}
+
+	for _, variant := range []Variant{VariantCtx, VariantPh} {
+		context := []byte("threshold-lib")
+		p1 := NewEd25519Sign(1, 2, partList, p1Data.ShareI, publicKey, hex.EncodeToString(message)).SetVariant(variant, context).SetSharePubKeyMap(p1Data.SharePubKeyMap)
+		p2 := NewEd25519Sign(2, 2, partList, p2Data.ShareI, publicKey, hex.EncodeToString(message)).SetVariant(variant, context)
+
+		p1Step1, _ := p1.SignStep1()
+		p2Step1, _ := p2.SignStep1()
+		p1Step2, _ := p1.SignStep2([]*tss.Message{p2Step1[1]})
+		p2Step2, _ := p2.SignStep2([]*tss.Message{p1Step1[2]})
+		si_1, _, _ := p1.SignStep3([]*tss.Message{p2Step2[1]})
+		si_2, _, _ := p2.SignStep3([]*tss.Message{p1Step2[2]})
+
+		signature, err := p1.Aggregate(map[int]*big.Int{1: si_1, 2: si_2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(publicKey, message, signature, variant, context) {
+			t.Fatal("variant signature verify fail", variant)
+		}
+		if stded25519.Verify(publicKey.Serialize(), message, signature) {
+			t.Fatal("variant signature must not verify as pure Ed25519", variant)
+		}
+	}
+
+	p1 := NewEd25519Sign(1, 2, partList, p1Data.ShareI, publicKey, hex.EncodeToString(message)).SetVariant(VariantCtx, nil)
+	if _, err := p1.SignStep1(); err == nil {
+		t.Fatal("Ed25519ctx requires a context")
+	}
+}
@@ -0,0 +1,343 @@
+package sign
+
+import (
+	stded25519 "crypto/ed25519"
+	cryptorand "crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/agl/ed25519/edwards25519"
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// FROST(Ed25519, SHA-512) ciphersuite, RFC 9591
+const frostContext = "FROST-ED25519-SHA512-v1"
+
+// FrostCommitment hiding and binding nonce commitments of one signer, published in the preprocessing phase
+type FrostCommitment struct {
+	Id      int
+	Hiding  *curves.ECPoint
+	Binding *curves.ECPoint
+}
+
+type frostNonce struct {
+	hiding  *big.Int
+	binding *big.Int
+	binds   *curves.ECPoint
+}
+
+// FrostSign FROST two-round threshold signer, nonces are preprocessed and signing is a single online round
+// any subset of at least Threshold devices can sign, the subset is given by the commitment list
+type FrostSign struct {
+	DeviceNumber int
+	Threshold    int
+	shareI       *big.Int
+	PublicKey    *edwards.PublicKey
+
+	nonces map[string]*frostNonce // unused nonces, key: serialized hiding commitment
+	rand   io.Reader
+}
+
+// NewFrostSign ShareI and PublicKey from KeyStep3Data
+func NewFrostSign(deviceNumber, threshold int, ShareI *big.Int, PublicKey *edwards.PublicKey) *FrostSign {
+	if deviceNumber <= 0 || threshold <= 0 || ShareI == nil || PublicKey == nil {
+		return nil
+	}
+	return &FrostSign{
+		DeviceNumber: deviceNumber,
+		Threshold:    threshold,
+		shareI:       ShareI,
+		PublicKey:    PublicKey,
+		nonces:       make(map[string]*frostNonce),
+		rand:         cryptorand.Reader,
+	}
+}
+
+// Preprocess generate n nonce pairs and broadcast the commitments, every nonce signs only once
+func (frost *FrostSign) Preprocess(n int) ([]*FrostCommitment, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("preprocess number error")
+	}
+	commitments := make([]*FrostCommitment, n)
+	for k := 0; k < n; k++ {
+		hiding, err := frost.nonceGenerate()
+		if err != nil {
+			return nil, err
+		}
+		binding, err := frost.nonceGenerate()
+		if err != nil {
+			return nil, err
+		}
+		cmt := &FrostCommitment{
+			Id:      frost.DeviceNumber,
+			Hiding:  curves.ScalarToPoint(curve, hiding),
+			Binding: curves.ScalarToPoint(curve, binding),
+		}
+		frost.nonces[hex.EncodeToString(serializeElement(cmt.Hiding))] = &frostNonce{hiding: hiding, binding: binding, binds: cmt.Binding}
+		commitments[k] = cmt
+	}
+	return commitments, nil
+}
+
+// Sign single online round, commitments contains one preprocessed commitment of every signer, message in hex
+// zi = di + ei*rho_i + lambda_i*si*c, the used nonce is deleted
+func (frost *FrostSign) Sign(message string, commitments []*FrostCommitment) (*big.Int, error) {
+	bytes, err := hex.DecodeString(message)
+	if err != nil {
+		return nil, err
+	}
+	list, err := sortCommitments(commitments)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) < frost.Threshold {
+		return nil, fmt.Errorf("signers number less than threshold")
+	}
+	var own *FrostCommitment
+	for _, cmt := range list {
+		if cmt.Id == frost.DeviceNumber {
+			own = cmt
+		}
+	}
+	if own == nil {
+		return nil, fmt.Errorf("commitment of %d not found", frost.DeviceNumber)
+	}
+	key := hex.EncodeToString(serializeElement(own.Hiding))
+	nonce, ok := frost.nonces[key]
+	if !ok || !nonce.binds.Equals(own.Binding) {
+		return nil, fmt.Errorf("nonce not found or already used")
+	}
+	delete(frost.nonces, key)
+
+	rho := frostBindingFactors(frost.PublicKey, list, bytes)
+	R, err := frostGroupCommitment(list, rho)
+	if err != nil {
+		return nil, err
+	}
+	c := encodedBytesToBigInt(challenge(nil, R, frost.PublicKey, bytes))
+	lambda := frostLagrangian(list, frost.DeviceNumber, frost.shareI)
+
+	// zi = di + ei*rho_i + lambda_i*si*c
+	zi := new(big.Int).Mul(nonce.binding, rho[frost.DeviceNumber])
+	zi.Add(zi, nonce.hiding)
+	zi.Add(zi, new(big.Int).Mul(lambda, c))
+	return zi.Mod(zi, curve.N), nil
+}
+
+// FrostVerifyShare zi*G == Di + rho_i*Ei + c*lambda_i*Yi, Yi = ShareI*G from KeyStep3Data.SharePubKeyMap
+func FrostVerifyShare(publicKey *edwards.PublicKey, message string, commitments []*FrostCommitment, i int, zi *big.Int, Yi *curves.ECPoint) bool {
+	if publicKey == nil || zi == nil || Yi == nil || zi.Sign() < 0 || zi.Cmp(curve.N) >= 0 {
+		return false
+	}
+	bytes, err := hex.DecodeString(message)
+	if err != nil {
+		return false
+	}
+	list, err := sortCommitments(commitments)
+	if err != nil {
+		return false
+	}
+	var own *FrostCommitment
+	for _, cmt := range list {
+		if cmt.Id == i {
+			own = cmt
+		}
+	}
+	if own == nil {
+		return false
+	}
+	rho := frostBindingFactors(publicKey, list, bytes)
+	R, err := frostGroupCommitment(list, rho)
+	if err != nil {
+		return false
+	}
+	c := encodedBytesToBigInt(challenge(nil, R, publicKey, bytes))
+	lambda := frostLagrangian(list, i, c)
+
+	right, err := own.Hiding.Add(own.Binding.ScalarMult(rho[i]))
+	if err != nil {
+		return false
+	}
+	right, err = right.Add(Yi.ScalarMult(lambda))
+	if err != nil {
+		return false
+	}
+	return curves.ScalarToPoint(curve, zi).Equals(right)
+}
+
+// FrostAggregate coordinator combines signature shares, key: device number
+// every share is checked with FrostVerifyShare, sharePubKeyMap is required, bad devices are reported by tss.InvalidPartialError
+// returns the RFC 8032 signature R || z, verified with crypto/ed25519
+func FrostAggregate(publicKey *edwards.PublicKey, message string, commitments []*FrostCommitment, shares map[int]*big.Int, sharePubKeyMap map[int]*curves.ECPoint) ([]byte, error) {
+	if publicKey == nil {
+		return nil, fmt.Errorf("public key is nil")
+	}
+	if sharePubKeyMap == nil {
+		return nil, fmt.Errorf("share public key map is nil")
+	}
+	bytes, err := hex.DecodeString(message)
+	if err != nil {
+		return nil, err
+	}
+	list, err := sortCommitments(commitments)
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) != len(list) {
+		return nil, fmt.Errorf("signature shares number error")
+	}
+	z := new(big.Int)
+	var invalid []int
+	for _, cmt := range list {
+		zi, ok := shares[cmt.Id]
+		if !ok {
+			return nil, fmt.Errorf("missing signature share of %d", cmt.Id)
+		}
+		if !FrostVerifyShare(publicKey, message, list, cmt.Id, zi, sharePubKeyMap[cmt.Id]) {
+			invalid = append(invalid, cmt.Id)
+			continue
+		}
+		z.Add(z, zi)
+	}
+	if len(invalid) > 0 {
+		return nil, &tss.InvalidPartialError{Ids: invalid}
+	}
+	z.Mod(z, curve.N)
+
+	R, err := frostGroupCommitment(list, frostBindingFactors(publicKey, list, bytes))
+	if err != nil {
+		return nil, err
+	}
+	signature := make([]byte, 0, stded25519.SignatureSize)
+	signature = append(signature, R.Serialize()...)
+	signature = append(signature, bigIntToEncodedBytes(z)[:]...)
+	if !stded25519.Verify(publicKey.Serialize(), bytes, signature) {
+		return nil, fmt.Errorf("aggregated signature verify fail")
+	}
+	return signature, nil
+}
+
+// nonceGenerate H3(random_bytes(32) || SerializeScalar(ShareI))
+func (frost *FrostSign) nonceGenerate() (*big.Int, error) {
+	random := make([]byte, 32)
+	_, err := io.ReadFull(frost.rand, random)
+	if err != nil {
+		return nil, err
+	}
+	return frostHash("nonce", random, bigIntToEncodedBytes(frost.shareI)[:]), nil
+}
+
+// sortCommitments copy sorted by device number, ids are unique and every point is a valid non-identity element
+func sortCommitments(commitments []*FrostCommitment) ([]*FrostCommitment, error) {
+	if len(commitments) == 0 {
+		return nil, fmt.Errorf("commitment list is empty")
+	}
+	list := make([]*FrostCommitment, len(commitments))
+	for k, cmt := range commitments {
+		if cmt == nil || cmt.Id <= 0 {
+			return nil, fmt.Errorf("commitment id error")
+		}
+		list[k] = cmt
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].Id < list[b].Id })
+	for k, cmt := range list {
+		if k > 0 && list[k-1].Id == cmt.Id {
+			return nil, fmt.Errorf("duplicate commitment of %d", cmt.Id)
+		}
+		if !validElement(cmt.Hiding) || !validElement(cmt.Binding) {
+			return nil, fmt.Errorf("invalid commitment of %d", cmt.Id)
+		}
+	}
+	return list, nil
+}
+
+// validElement on the curve, not the identity and in the prime order subgroup
+func validElement(p *curves.ECPoint) bool {
+	if p == nil || p.X == nil || p.Y == nil || !curve.IsOnCurve(p.X, p.Y) {
+		return false
+	}
+	if p.X.Sign() == 0 && p.Y.Cmp(big.NewInt(1)) == 0 {
+		return false
+	}
+	x, y := curve.ScalarMult(p.X, p.Y, curve.N.Bytes())
+	return x.Sign() == 0 && y.Cmp(big.NewInt(1)) == 0
+}
+
+// frostBindingFactors rho_i = H1(PK || H4(msg) || H5(encoded commitment list) || SerializeScalar(i))
+func frostBindingFactors(publicKey *edwards.PublicKey, list []*FrostCommitment, message []byte) map[int]*big.Int {
+	var encoded []byte
+	for _, cmt := range list {
+		encoded = append(encoded, bigIntToEncodedBytes(big.NewInt(int64(cmt.Id)))[:]...)
+		encoded = append(encoded, serializeElement(cmt.Hiding)...)
+		encoded = append(encoded, serializeElement(cmt.Binding)...)
+	}
+	prefix := append([]byte{}, publicKey.Serialize()...)
+	prefix = append(prefix, frostDigest("msg", message)...)
+	prefix = append(prefix, frostDigest("com", encoded)...)
+
+	rho := make(map[int]*big.Int, len(list))
+	for _, cmt := range list {
+		rho[cmt.Id] = frostHash("rho", prefix, bigIntToEncodedBytes(big.NewInt(int64(cmt.Id)))[:])
+	}
+	return rho
+}
+
+// frostGroupCommitment R = sum(Di + rho_i*Ei)
+func frostGroupCommitment(list []*FrostCommitment, rho map[int]*big.Int) (*edwards.PublicKey, error) {
+	var R *curves.ECPoint
+	for _, cmt := range list {
+		Ri, err := cmt.Hiding.Add(cmt.Binding.ScalarMult(rho[cmt.Id]))
+		if err != nil {
+			return nil, err
+		}
+		if R == nil {
+			R = Ri
+			continue
+		}
+		R, err = R.Add(Ri)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return edwards.NewPublicKey(R.X, R.Y), nil
+}
+
+// frostLagrangian lambda_i*y over the signer ids
+func frostLagrangian(list []*FrostCommitment, i int, y *big.Int) *big.Int {
+	xList := make([]*big.Int, len(list))
+	for k, cmt := range list {
+		xList[k] = big.NewInt(int64(cmt.Id))
+	}
+	return vss.CalLagrangian(curve, big.NewInt(int64(i)), y, xList)
+}
+
+// frostDigest SHA-512(contextString || tag || m)
+func frostDigest(tag string, m ...[]byte) []byte {
+	h := sha512.New()
+	h.Write([]byte(frostContext))
+	h.Write([]byte(tag))
+	for _, b := range m {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// frostHash frostDigest interpreted as a little endian integer mod L
+func frostHash(tag string, m ...[]byte) *big.Int {
+	var digest [64]byte
+	copy(digest[:], frostDigest(tag, m...))
+	reduced := new([32]byte)
+	edwards25519.ScReduce(reduced, &digest)
+	return encodedBytesToBigInt(reduced)
+}
+
+func serializeElement(p *curves.ECPoint) []byte {
+	return edwards.NewPublicKey(p.X, p.Y).Serialize()
+}
@@ -1,47 +1,29 @@
 package sign
 
 import (
-	"encoding/json"
-	"fmt"
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"math/big"
+
 	"github.com/okx/threshold-lib/crypto"
-	"github.com/okx/threshold-lib/crypto/commitment"
-	"github.com/okx/threshold-lib/crypto/curves"
-	"github.com/okx/threshold-lib/tss"
 )
 
-type Step1Data struct {
-	C commitment.Commitment
-}
-
-// SignStep1 p2p send Ri commitment
-func (ed25519 *Ed25519Sign) SignStep1() (map[int]*tss.Message, error) {
-	if ed25519.RoundNumber != 1 {
-		return nil, fmt.Errorf("round error")
+// nonce random ki, or hedged nonce bound to this signing session
+func (ed25519 *Ed25519Sign) nonce(wi *big.Int) (*big.Int, error) {
+	if err := checkVariant(ed25519.variant, ed25519.context); err != nil {
+		return nil, err
 	}
-	ed25519.ki = crypto.RandomNum(curve.N)
-	Ri := curves.ScalarToPoint(curve, ed25519.ki)
-	// Ri commitment
-	cmt := commitment.NewCommitment(Ri.X, Ri.Y)
-	ed25519.cmtD = cmt.Msg
-	ed25519.RoundNumber = 2
-
-	out := make(map[int]*tss.Message, ed25519.Threshold-1)
-	for _, i := range ed25519.partList {
-		if i == ed25519.DeviceNumber {
-			continue
-		}
-		// p2p send message
-		data := Step1Data{C: cmt.C}
-		bytes, err := json.Marshal(data)
-		if err != nil {
-			return nil, err
-		}
-		message := &tss.Message{
-			From: ed25519.DeviceNumber,
-			To:   i,
-			Data: string(bytes),
-		}
-		out[i] = message
+	if !ed25519.hedged {
+		return crypto.RandomNum(curve.N), nil
+	}
+	rand := ed25519.nonceRand
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	ids := append([]int{ed25519.DeviceNumber, ed25519.Epoch()}, ed25519.PartList()...)
+	session := make([]byte, 8*len(ids))
+	for i, v := range ids {
+		binary.BigEndian.PutUint64(session[8*i:], uint64(v))
 	}
-	return out, nil
+	return crypto.HedgedNonce(wi, curve.N, rand, []byte("ed25519"), session, []byte(ed25519.Message()), ed25519.nonceId)
 }
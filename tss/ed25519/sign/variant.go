@@ -0,0 +1,102 @@
+package sign
+
+import (
+	stded25519 "crypto/ed25519"
+	"crypto/sha512"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+)
+
+// Variant RFC 8032 Ed25519 variant
+type Variant int
+
+const (
+	VariantPure Variant = iota // Ed25519, h = SHA512(R || A || M)
+	VariantCtx                 // Ed25519ctx, h = SHA512(dom2(0, context) || R || A || M)
+	VariantPh                  // Ed25519ph, h = SHA512(dom2(1, context) || R || A || SHA512(M))
+)
+
+const dom2Prefix = "SigEd25519 no Ed25519 collisions"
+
+// SetVariant select Ed25519ctx or Ed25519ph, context is at most 255 bytes and must not be empty for Ed25519ctx
+// all participants must use the same variant and context, the message is still the hex encoded M, not SHA512(M)
+func (ed25519 *Ed25519Sign) SetVariant(variant Variant, context []byte) *Ed25519Sign {
+	ed25519.variant = variant
+	ed25519.context = context
+	return ed25519
+}
+
+// checkVariant context length rules of RFC 8032
+func checkVariant(variant Variant, context []byte) error {
+	switch variant {
+	case VariantPure:
+		if len(context) != 0 {
+			return fmt.Errorf("Ed25519 does not take a context")
+		}
+	case VariantCtx:
+		if len(context) == 0 || len(context) > 255 {
+			return fmt.Errorf("Ed25519ctx context length error")
+		}
+	case VariantPh:
+		if len(context) > 255 {
+			return fmt.Errorf("Ed25519ph context length error")
+		}
+	default:
+		return fmt.Errorf("unknown Ed25519 variant %d", variant)
+	}
+	return nil
+}
+
+// dom2 "SigEd25519 no Ed25519 collisions" || phflag || len(context) || context, empty for pure Ed25519
+// the message is replaced by SHA512(M) for Ed25519ph
+func dom2(variant Variant, context, message []byte) ([]byte, []byte) {
+	if variant == VariantPure {
+		return nil, message
+	}
+	dom := []byte(dom2Prefix)
+	if variant == VariantPh {
+		digest := sha512.Sum512(message)
+		message = digest[:]
+		dom = append(dom, 1)
+	} else {
+		dom = append(dom, 0)
+	}
+	dom = append(dom, byte(len(context)))
+	return append(dom, context...), message
+}
+
+// Verify RFC 8032 signature of the given variant, s*G == R + h*A
+func Verify(publicKey *edwards.PublicKey, message, signature []byte, variant Variant, context []byte) bool {
+	if publicKey == nil || len(signature) != stded25519.SignatureSize || checkVariant(variant, context) != nil {
+		return false
+	}
+	if variant == VariantPure {
+		return stded25519.Verify(publicKey.Serialize(), message, signature)
+	}
+	R, err := edwards.ParsePubKey(signature[:32])
+	if err != nil {
+		return false
+	}
+	s := encodedBytesToBigInt(copyBytes(signature[32:]))
+	if s.Cmp(curve.N) >= 0 {
+		return false
+	}
+	dom, m := dom2(variant, context, message)
+	h := encodedBytesToBigInt(challenge(dom, R, publicKey, m))
+
+	A, err := curves.NewECPoint(curve, publicKey.X, publicKey.Y)
+	if err != nil {
+		return false
+	}
+	RR, err := curves.NewECPoint(curve, R.X, R.Y)
+	if err != nil {
+		return false
+	}
+	right, err := RR.Add(A.ScalarMult(h))
+	if err != nil {
+		return false
+	}
+	return curves.ScalarToPoint(curve, s).Equals(right)
+}
@@ -0,0 +1,45 @@
+package tss
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+)
+
+// EpochHash sha256(prevHash || epoch || PublicKey || SharePubKeyMap sorted by id)
+// chains the public outputs of the key share generations, every device of the same epoch computes the same hash,
+// the round messages are not hashed, it identifies an epoch and does not authenticate the transcript
+func EpochHash(prevHash string, epoch int, publicKey *curves.ECPoint, sharePubKeyMap map[int]*curves.ECPoint) string {
+	hash := sha256.New()
+	hash.Write([]byte(prevHash))
+	hash.Write(uint64Bytes(uint64(epoch)))
+	writePoint(hash, publicKey)
+
+	ids := make([]int, 0, len(sharePubKeyMap))
+	for id := range sharePubKeyMap {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		hash.Write(uint64Bytes(uint64(id)))
+		writePoint(hash, sharePubKeyMap[id])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func writePoint(w interface{ Write([]byte) (int, error) }, point *curves.ECPoint) {
+	size := (point.Curve.Params().BitSize + 7) / 8
+	buf := make([]byte, 2*size)
+	point.X.FillBytes(buf[:size])
+	point.Y.FillBytes(buf[size:])
+	w.Write(buf)
+}
+
+func uint64Bytes(i uint64) []byte {
+	bytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(bytes, i)
+	return bytes
+}
@@ -0,0 +1,31 @@
+package tss
+
+import (
+	"fmt"
+)
+
+// InvalidPartialError devices whose partial signature failed verification, exclude them and sign again
+type InvalidPartialError struct {
+	Ids []int
+}
+
+func (e *InvalidPartialError) Error() string {
+	return fmt.Sprintf("invalid partial signatures from %v", e.Ids)
+}
+
+// InvalidDealerError reshare dealers whose sub-share, commitment or proof failed verification, exclude them and reshare again
+type InvalidDealerError struct {
+	Ids []int
+}
+
+func (e *InvalidDealerError) Error() string {
+	return fmt.Sprintf("invalid reshare dealers %v", e.Ids)
+}
+
+// CheckEpoch epoch of a received message must equal the epoch of own key share
+func CheckEpoch(own, received int) error {
+	if own != received {
+		return fmt.Errorf("epoch mismatch, refuse to mix key shares of epoch %d and %d", own, received)
+	}
+	return nil
+}
@@ -42,9 +42,10 @@ func NewTssKey(shareI *big.Int, publicKey *curves.ECPoint, chaincode string) (*T
 }
 
 // NewChildKey like bip32 non-hardened derivation
+// hardened derivation needs HMAC-SHA512 over the full private key, which no party holds
 func (tssKey *TssKey) NewChildKey(childIdx uint32) (*TssKey, error) {
 	if childIdx >= uint32(0x80000000) { // 2^31
-		return nil, fmt.Errorf("hardened derivation is unsupported")
+		return nil, fmt.Errorf("hardened derivation is unsupported, index %d >= 2^31", childIdx)
 	}
 	curve := tssKey.publicKey.Curve
 	intermediary, err := calPrivateOffset(tssKey.publicKey.X.Bytes(), tssKey.chaincode, childIdx)
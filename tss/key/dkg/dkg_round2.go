@@ -27,6 +27,11 @@ func (info *SetupInfo) DKGStep2(msgs []*tss.Message) (map[int]*tss.Message, erro
 		if err != nil {
 			return nil, err
 		}
+		// dkg outputs epoch 0
+		err = tss.CheckEpoch(0, content.Epoch)
+		if err != nil {
+			return nil, err
+		}
 		info.commitmentMap[msg.From] = *content.C
 	}
 
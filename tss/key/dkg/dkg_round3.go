@@ -43,6 +43,10 @@ func (info *SetupInfo) DKGStep3(msgs []*tss.Message) (*tss.KeyStep3Data, error)
 		if err != nil {
 			return nil, err
 		}
+		err = tss.CheckEpoch(0, data.Epoch)
+		if err != nil {
+			return nil, err
+		}
 		// check verifiers commitment
 		hashCommit := commitment.HashCommitment{}
 		hashCommit.C = info.commitmentMap[msg.From]
@@ -121,6 +125,8 @@ func (info *SetupInfo) DKGStep3(msgs []*tss.Message) (*tss.KeyStep3Data, error)
 		PublicKey:      info.publicKey,
 		ChainCode:      hex.EncodeToString(chaincode.Bytes()),
 		SharePubKeyMap: sharePubKeyMap,
+		Epoch:          0,
+		EpochHash:      tss.EpochHash("", 0, info.publicKey, sharePubKeyMap),
 	}
 	return content, nil
 }
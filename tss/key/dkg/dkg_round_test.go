@@ -1,10 +1,13 @@
 package dkg
 
 import (
+	"crypto/elliptic"
 	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
 	"github.com/okx/threshold-lib/tss"
 )
 
@@ -88,3 +91,37 @@ func TestKeyGen2_4(t *testing.T) {
 	fmt.Println("setUp3", p3SaveData, p3SaveData.PublicKey)
 	fmt.Println("setUp4", p4SaveData, p4SaveData.PublicKey)
 }
+
+func TestKeyGenRegisteredCurve(t *testing.T) {
+	curve := elliptic.P384()
+	// registered once per process, the registry has no removal
+	if _, ok := curves.LookupByName("P-384"); !ok {
+		if err := curves.Register(curves.NewWeierstrassDefinition("P-384", curve, big.NewInt(-3))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setUp1 := NewSetUp(1, 2, curve)
+	setUp2 := NewSetUp(2, 2, curve)
+
+	msgs1_1, _ := setUp1.DKGStep1()
+	msgs2_1, _ := setUp2.DKGStep1()
+	msgs1_2, err := setUp1.DKGStep2([]*tss.Message{msgs2_1[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs2_2, err := setUp2.DKGStep2([]*tss.Message{msgs1_1[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p1SaveData, err := setUp1.DKGStep3([]*tss.Message{msgs2_2[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2SaveData, err := setUp2.DKGStep3([]*tss.Message{msgs1_2[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p1SaveData.PublicKey.Equals(p2SaveData.PublicKey) || curves.GetCurveName(p1SaveData.PublicKey.Curve) != "P-384" {
+		t.Fatal("public key mismatch")
+	}
+}
@@ -0,0 +1,53 @@
+package reshare
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// chaincodeToInt 0x01 || chaincode, keep leading zero bytes in the commitment, empty chaincode is 0
+func chaincodeToInt(chaincode string) (*big.Int, error) {
+	if chaincode == "" {
+		return big.NewInt(0), nil
+	}
+	bytes, err := hex.DecodeString(chaincode)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(append([]byte{0x01}, bytes...)), nil
+}
+
+func intToChaincode(c *big.Int) (string, error) {
+	if c.Sign() == 0 {
+		return "", nil
+	}
+	bytes := c.Bytes()
+	if bytes[0] != 0x01 {
+		return "", fmt.Errorf("invalid chaincode commitment")
+	}
+	return hex.EncodeToString(bytes[1:]), nil
+}
+
+// agreeChaincode all committed chaincodes must equal own chaincode,
+// a device without chaincode adopts the committed one if all senders agree, checked in device order
+func agreeChaincode(own *big.Int, committed map[int]*big.Int) (string, error) {
+	ids := make([]int, 0, len(committed))
+	for id := range committed {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	agreed := own
+	set := own.Sign() != 0
+	for _, from := range ids {
+		c := committed[from]
+		if !set {
+			agreed, set = c, true
+		}
+		if c.Cmp(agreed) != 0 {
+			return "", fmt.Errorf("chaincode mismatch from participant %d", from)
+		}
+	}
+	return intToChaincode(agreed)
+}
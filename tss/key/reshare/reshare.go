@@ -0,0 +1,204 @@
+package reshare
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/okx/threshold-lib/crypto/commitment"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+)
+
+// ReshareInfo reshare from old (t, n) committee to new (t', n') committee
+// old holders in oldPartList deal sub-shares of their lagrangian weighted secrets to newPartList
+type ReshareInfo struct {
+	DeviceNumber int
+	Threshold    int // new threshold t'
+	RoundNumber  int
+
+	curve          elliptic.Curve
+	oldPartList    []int // old key share holders, number equals old threshold t
+	newPartList    []int // new committee, n' devices
+	ui             *big.Int
+	publicKey      *curves.ECPoint
+	sharePubKeyMap map[int]*curves.ECPoint // old ShareI*G map
+	epoch          int                     // epoch of the old key share
+	epochHash      string                  // epoch hash of the old key share
+	chaincode      string                  // unchanged by reshare, committed by old holders
+
+	verifiers     []*curves.ECPoint
+	secretShares  map[int]*vss.Share
+	deC           *commitment.Witness
+	commitmentMap map[int]commitment.Commitment
+}
+
+// NewReshare ShareI and ChainCode are required for devices in oldPartList, ShareI is otherwise ignored
+// SharePubKeyMap is the old ShareI*G map, used to verify dealers, ChainCode is the stored KeyStep3Data.ChainCode
+func NewReshare(deviceNumber, threshold int, oldPartList, newPartList []int, ShareI *big.Int, PublicKey *curves.ECPoint, SharePubKeyMap map[int]*curves.ECPoint, ChainCode string) *ReshareInfo {
+	if threshold < 2 || len(newPartList) < threshold || len(oldPartList) < 2 || PublicKey == nil || SharePubKeyMap == nil {
+		panic(fmt.Errorf("NewReshare params error"))
+	}
+	if !uniquePositive(oldPartList) || !uniquePositive(newPartList) {
+		panic(fmt.Errorf("NewReshare params error, invalid device list"))
+	}
+	isOld, isNew := contains(oldPartList, deviceNumber), contains(newPartList, deviceNumber)
+	if !isOld && !isNew {
+		panic(fmt.Errorf("NewReshare params error, device not in reshare"))
+	}
+	for _, id := range oldPartList {
+		if _, ok := SharePubKeyMap[id]; !ok {
+			panic(fmt.Errorf("NewReshare params error, missing share public key %d", id))
+		}
+	}
+	curve := PublicKey.Curve
+	info := &ReshareInfo{
+		DeviceNumber:   deviceNumber,
+		Threshold:      threshold,
+		RoundNumber:    1,
+		curve:          curve,
+		oldPartList:    oldPartList,
+		newPartList:    newPartList,
+		publicKey:      PublicKey,
+		sharePubKeyMap: SharePubKeyMap,
+		chaincode:      ChainCode,
+	}
+	if isOld {
+		if ShareI == nil {
+			panic(fmt.Errorf("NewReshare params error, ShareI is nil"))
+		}
+		if ChainCode == "" {
+			panic(fmt.Errorf("NewReshare params error, ChainCode is empty"))
+		}
+		// lagrangian interpolation ui, private key = sum(ui)
+		info.ui = vss.CalLagrangian(curve, big.NewInt(int64(deviceNumber)), ShareI, toBigInts(oldPartList))
+	}
+	return info
+}
+
+// NewJoin a new device without any key share joins the reshare
+// SharePubKeyMap is the old ShareI*G map published by the old committee, it must interpolate to PublicKey
+// every dealer's commitment is verified against its own share public key, a bad dealer is reported by tss.InvalidDealerError
+func NewJoin(deviceNumber, threshold int, oldPartList, newPartList []int, PublicKey *curves.ECPoint, SharePubKeyMap map[int]*curves.ECPoint) *ReshareInfo {
+	if threshold < 2 || len(newPartList) < threshold || len(oldPartList) < 2 || PublicKey == nil || SharePubKeyMap == nil {
+		panic(fmt.Errorf("NewJoin params error"))
+	}
+	if !uniquePositive(oldPartList) || !uniquePositive(newPartList) {
+		panic(fmt.Errorf("NewJoin params error, invalid device list"))
+	}
+	if contains(oldPartList, deviceNumber) || !contains(newPartList, deviceNumber) {
+		panic(fmt.Errorf("NewJoin params error, device must be a new member"))
+	}
+	for _, id := range oldPartList {
+		if _, ok := SharePubKeyMap[id]; !ok {
+			panic(fmt.Errorf("NewJoin params error, missing share public key %d", id))
+		}
+	}
+	info := &ReshareInfo{
+		DeviceNumber:   deviceNumber,
+		Threshold:      threshold,
+		RoundNumber:    1,
+		curve:          PublicKey.Curve,
+		oldPartList:    oldPartList,
+		newPartList:    newPartList,
+		publicKey:      PublicKey,
+		sharePubKeyMap: SharePubKeyMap,
+	}
+	// sum(lagrangian(id) * ShareI*G) of the dealers is the group public key
+	sum := curves.ScalarToPoint(info.curve, big.NewInt(0))
+	for _, id := range oldPartList {
+		var err error
+		sum, err = sum.Add(info.dealerPubKey(id))
+		if err != nil {
+			panic(fmt.Errorf("NewJoin params error, %v", err))
+		}
+	}
+	if !sum.Equals(PublicKey) {
+		panic(fmt.Errorf("NewJoin params error, SharePubKeyMap does not match PublicKey"))
+	}
+	return info
+}
+
+// SetEpoch epoch and epoch hash of the old key share, a joining device uses the values published by the old committee
+func (info *ReshareInfo) SetEpoch(epoch int, epochHash string) *ReshareInfo {
+	info.epoch = epoch
+	info.epochHash = epochHash
+	return info
+}
+
+// SetChainCode chaincode expected by a device outside oldPartList, if it is not set the device adopts the chaincode
+// committed by the old holders, devices in oldPartList pass it to NewReshare
+func (info *ReshareInfo) SetChainCode(chaincode string) *ReshareInfo {
+	info.chaincode = chaincode
+	return info
+}
+
+// RemovedList old committee devices not in the new committee, from oldPartList and the old SharePubKeyMap
+// reshare does not invalidate old shares, any t of them still recover the private key, and every removed dealer
+// knows its own sub-shares of the new shares, removal takes effect only after the caller destroys the persisted
+// KeyStep3Data of every removed device and the new committee runs a follow-up refresh with NewRefresh
+func (info *ReshareInfo) RemovedList() []int {
+	old := make(map[int]struct{}, len(info.oldPartList)+len(info.sharePubKeyMap))
+	for _, id := range info.oldPartList {
+		old[id] = struct{}{}
+	}
+	for id := range info.sharePubKeyMap {
+		old[id] = struct{}{}
+	}
+	var removed []int
+	for id := range old {
+		if !contains(info.newPartList, id) {
+			removed = append(removed, id)
+		}
+	}
+	sort.Ints(removed)
+	return removed
+}
+
+// isDealer old key share holder
+func (info *ReshareInfo) isDealer() bool {
+	return contains(info.oldPartList, info.DeviceNumber)
+}
+
+// isReceiver new committee member
+func (info *ReshareInfo) isReceiver() bool {
+	return contains(info.newPartList, info.DeviceNumber)
+}
+
+// dealerPubKey expected ui*G = lagrangian(id) * ShareI*G
+func (info *ReshareInfo) dealerPubKey(id int) *curves.ECPoint {
+	lambda := vss.CalLagrangian(info.curve, big.NewInt(int64(id)), big.NewInt(1), toBigInts(info.oldPartList))
+	return info.sharePubKeyMap[id].ScalarMult(lambda)
+}
+
+func contains(list []int, id int) bool {
+	for _, v := range list {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func uniquePositive(list []int) bool {
+	seen := make(map[int]struct{}, len(list))
+	for _, v := range list {
+		if v <= 0 {
+			return false
+		}
+		if _, ok := seen[v]; ok {
+			return false
+		}
+		seen[v] = struct{}{}
+	}
+	return true
+}
+
+func toBigInts(list []int) []*big.Int {
+	out := make([]*big.Int, len(list))
+	for i, v := range list {
+		out[i] = big.NewInt(int64(v))
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package reshare
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/commitment"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// ReshareStep1 old holders p2p send verifiers commitment to new committee
+func (info *ReshareInfo) ReshareStep1() (map[int]*tss.Message, error) {
+	if info.RoundNumber != 1 {
+		return nil, fmt.Errorf("round error")
+	}
+	out := make(map[int]*tss.Message, len(info.newPartList))
+	if !info.isDealer() {
+		// new member only receives
+		info.RoundNumber = 2
+		return out, nil
+	}
+	feldman, err := vss.NewFeldman(info.Threshold, len(info.newPartList), info.curve)
+	if err != nil {
+		return nil, err
+	}
+	// sub-shares of ui for new committee
+	verifiers, shares, err := feldman.EvaluateWithIds(info.ui, info.newPartList)
+	if err != nil {
+		return nil, err
+	}
+
+	// compute chaincode and verifiers commitment
+	chaincode, err := chaincodeToInt(info.chaincode)
+	if err != nil {
+		return nil, err
+	}
+	input := []*big.Int{chaincode}
+	for i := 0; i < len(verifiers); i++ {
+		input = append(input, verifiers[i].X, verifiers[i].Y)
+	}
+	hashCommitment := commitment.NewCommitment(input...)
+
+	info.deC = &hashCommitment.Msg
+	info.verifiers = verifiers
+	info.secretShares = make(map[int]*vss.Share, len(shares))
+	for i, id := range info.newPartList {
+		info.secretShares[id] = shares[i]
+	}
+	info.RoundNumber = 2
+
+	for _, id := range info.newPartList {
+		if id == info.DeviceNumber {
+			continue
+		}
+		content := tss.KeyStep1Data{C: &hashCommitment.C, Epoch: info.epoch}
+		bytes, err := json.Marshal(content)
+		if err != nil {
+			return nil, err
+		}
+		message := &tss.Message{
+			From: info.DeviceNumber,
+			To:   id,
+			Data: string(bytes),
+		}
+		out[id] = message
+	}
+	return out, nil
+}
@@ -0,0 +1,86 @@
+package reshare
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/okx/threshold-lib/crypto/commitment"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/schnorr"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// ReshareStep2 new members receive commitments, old holders send sub-shares and schnorr proof for ui
+func (info *ReshareInfo) ReshareStep2(msgs []*tss.Message) (map[int]*tss.Message, error) {
+	if info.RoundNumber != 2 {
+		return nil, fmt.Errorf("round error")
+	}
+	if len(msgs) != info.expectedMessages() {
+		return nil, fmt.Errorf("messages number error")
+	}
+	info.commitmentMap = make(map[int]commitment.Commitment, len(msgs))
+	for _, msg := range msgs {
+		if msg.To != info.DeviceNumber || !contains(info.oldPartList, msg.From) {
+			return nil, fmt.Errorf("message sending error")
+		}
+		var content tss.KeyStep1Data
+		err := json.Unmarshal([]byte(msg.Data), &content)
+		if err != nil {
+			return nil, err
+		}
+		err = tss.CheckEpoch(info.epoch, content.Epoch)
+		if err != nil {
+			return nil, err
+		}
+		info.commitmentMap[msg.From] = *content.C
+	}
+	info.RoundNumber = 3
+
+	out := make(map[int]*tss.Message, len(info.newPartList))
+	if !info.isDealer() {
+		return out, nil
+	}
+	uiG := curves.ScalarToPoint(info.curve, info.ui)
+	proof, err := schnorr.Prove(info.ui, uiG)
+	if err != nil {
+		return nil, err
+	}
+	if !info.isReceiver() {
+		// removed device, only the in-memory ui is cleared, the caller must destroy the stored KeyStep3Data
+		info.ui = nil
+		info.RoundNumber = -1
+	}
+	for _, id := range info.newPartList {
+		if id == info.DeviceNumber {
+			continue
+		}
+		content := tss.KeyStep2Data{
+			Witness: info.deC,
+			Share:   info.secretShares[id],
+			Proof:   proof,
+			Epoch:   info.epoch,
+		}
+		bytes, err := json.Marshal(content)
+		if err != nil {
+			return nil, err
+		}
+		message := &tss.Message{
+			From: info.DeviceNumber,
+			To:   id,
+			Data: string(bytes),
+		}
+		out[id] = message
+	}
+	return out, nil
+}
+
+// expectedMessages number of old holders sending to this device
+func (info *ReshareInfo) expectedMessages() int {
+	if !info.isReceiver() {
+		return 0
+	}
+	if info.isDealer() {
+		return len(info.oldPartList) - 1
+	}
+	return len(info.oldPartList)
+}
@@ -0,0 +1,167 @@
+package reshare
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/okx/threshold-lib/crypto/commitment"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/schnorr"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/key/dkg"
+)
+
+// ReshareStep3 new members verify sub-shares against old SharePubKeyMap
+// return new key share information, chaincode is checked unchanged, bad dealers are reported by tss.InvalidDealerError
+func (info *ReshareInfo) ReshareStep3(msgs []*tss.Message) (*tss.KeyStep3Data, error) {
+	if info.RoundNumber != 3 {
+		return nil, fmt.Errorf("round error")
+	}
+	if !info.isReceiver() {
+		return nil, fmt.Errorf("device is not in the new committee")
+	}
+	if len(msgs) != info.expectedMessages() {
+		return nil, fmt.Errorf("messages number error")
+	}
+
+	curve := info.curve
+	feldman, err := vss.NewFeldman(info.Threshold, len(info.newPartList), curve)
+	if err != nil {
+		return nil, err
+	}
+
+	verifiers := make(map[int][]*curves.ECPoint, len(info.oldPartList))
+	chaincodes := make(map[int]*big.Int, len(info.oldPartList))
+	var invalid []int // dealers failing verifyDealer
+	xi := big.NewInt(0)
+	if info.isDealer() {
+		verifiers[info.DeviceNumber] = info.verifiers
+		xi = new(big.Int).Set(info.secretShares[info.DeviceNumber].Y)
+	}
+	for _, msg := range msgs {
+		if msg.To != info.DeviceNumber || !contains(info.oldPartList, msg.From) {
+			return nil, fmt.Errorf("message sending error")
+		}
+		if _, ok := verifiers[msg.From]; ok {
+			return nil, fmt.Errorf("duplicate message from %d", msg.From)
+		}
+		var content tss.KeyStep2Data
+		err := json.Unmarshal([]byte(msg.Data), &content)
+		if err != nil {
+			return nil, err
+		}
+		if content.Witness == nil || content.Share == nil || content.Share.Id == nil || content.Share.Y == nil {
+			return nil, fmt.Errorf("message content error")
+		}
+		err = tss.CheckEpoch(info.epoch, content.Epoch)
+		if err != nil {
+			return nil, err
+		}
+		hashCommit := commitment.HashCommitment{}
+		hashCommit.C = info.commitmentMap[msg.From]
+		hashCommit.Msg = *content.Witness
+		ok, D := hashCommit.Open()
+		if !ok || len(D) == 0 {
+			return nil, fmt.Errorf("commitment DeCommit fail")
+		}
+		chaincodes[msg.From] = D[0]
+		verifiers[msg.From], err = dkg.UnmarshalVerifiers(curve, D[1:], info.Threshold)
+		if err != nil {
+			return nil, err
+		}
+		if !info.verifyDealer(feldman, msg.From, content, verifiers[msg.From]) {
+			invalid = append(invalid, msg.From)
+			continue
+		}
+		xi = new(big.Int).Add(xi, content.Share.Y)
+	}
+	if len(invalid) > 0 {
+		sort.Ints(invalid)
+		return nil, &tss.InvalidDealerError{Ids: invalid}
+	}
+	if len(verifiers) != len(info.oldPartList) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	xi = new(big.Int).Mod(xi, curve.Params().N)
+
+	v := make([]*curves.ECPoint, info.Threshold)
+	for j := 0; j < info.Threshold; j++ {
+		v[j] = curves.ScalarToPoint(curve, big.NewInt(0))
+		for _, verifier := range verifiers {
+			v[j], err = v[j].Add(verifier[j])
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	// reshare does not change the publicKey, the only check for joining device
+	if !v[0].Equals(info.publicKey) {
+		return nil, fmt.Errorf("public key recalculation error")
+	}
+
+	sharePubKeyMap := make(map[int]*curves.ECPoint, len(info.newPartList))
+	for _, k := range info.newPartList {
+		Yi := v[0]
+		tmp := big.NewInt(1)
+		for i := 1; i < info.Threshold; i++ {
+			tmp = tmp.Mul(tmp, big.NewInt(int64(k)))
+			point := v[i].ScalarMult(tmp)
+			Yi, err = Yi.Add(point)
+			if err != nil {
+				return nil, err
+			}
+		}
+		sharePubKeyMap[k] = Yi
+	}
+	xiG := curves.ScalarToPoint(curve, xi)
+	if !sharePubKeyMap[info.DeviceNumber].Equals(xiG) {
+		return nil, fmt.Errorf("public key calculation error")
+	}
+
+	// all old holders must commit the same chaincode
+	ownChaincode, err := chaincodeToInt(info.chaincode)
+	if err != nil {
+		return nil, err
+	}
+	chaincode, err := agreeChaincode(ownChaincode, chaincodes)
+	if err != nil {
+		return nil, err
+	}
+	if chaincode == "" {
+		return nil, fmt.Errorf("chaincode is empty")
+	}
+
+	content := &tss.KeyStep3Data{
+		Id:             info.DeviceNumber,
+		ShareI:         xi,
+		PublicKey:      info.publicKey,
+		ChainCode:      chaincode,
+		SharePubKeyMap: sharePubKeyMap,
+		Epoch:          info.epoch + 1,
+		EpochHash:      tss.EpochHash(info.epochHash, info.epoch+1, info.publicKey, sharePubKeyMap),
+	}
+	return content, nil
+}
+
+// verifyDealer sub-share at this device number passes feldman verification, the committed secret ui*G equals
+// the lagrangian weighted old share public key of the dealer, and the dealer proves knowledge of ui
+func (info *ReshareInfo) verifyDealer(feldman *vss.Feldman, from int, content tss.KeyStep2Data, verifiers []*curves.ECPoint) bool {
+	if content.Share.Id.Cmp(big.NewInt(int64(info.DeviceNumber))) != 0 {
+		return false
+	}
+	if ok, err := feldman.Verify(content.Share, verifiers); !ok || err != nil {
+		return false
+	}
+	ujPoint := verifiers[0]
+	if !ujPoint.Equals(info.dealerPubKey(from)) {
+		return false
+	}
+	point, err := curves.NewECPoint(info.curve, ujPoint.X, ujPoint.Y)
+	if err != nil {
+		return false
+	}
+	return schnorr.Verify(content.Proof, point)
+}
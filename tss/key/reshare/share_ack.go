@@ -0,0 +1,73 @@
+package reshare
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/schnorr"
+	"github.com/okx/threshold-lib/tss"
+)
+
+var ackLabel = new(big.Int).SetBytes([]byte("share acknowledgement"))
+
+// ShareAck epoch-transition acknowledgement only, after refresh each device acknowledges that it received and
+// stored its key share of the new epoch, the proof of knowledge of the new share is bound to the new epoch and
+// epoch hash, it is not a deletion acknowledgement and cannot prove that the key share of the previous epoch was deleted
+type ShareAck struct {
+	Id        int
+	Epoch     int
+	EpochHash string
+	Proof     *schnorr.Proof
+}
+
+// NewShareAck call after the new key share is stored, keyData is the new key share
+func NewShareAck(keyData *tss.KeyStep3Data) (*ShareAck, error) {
+	if keyData == nil || keyData.ShareI == nil || keyData.PublicKey == nil {
+		return nil, fmt.Errorf("NewShareAck parameters error")
+	}
+	sessionId, err := ackSessionId(keyData.Epoch, keyData.EpochHash)
+	if err != nil {
+		return nil, err
+	}
+	X := curves.ScalarToPoint(keyData.PublicKey.Curve, keyData.ShareI)
+	proof, err := schnorr.ProveWithId(sessionId, keyData.ShareI, X)
+	if err != nil {
+		return nil, err
+	}
+	return &ShareAck{
+		Id:        keyData.Id,
+		Epoch:     keyData.Epoch,
+		EpochHash: keyData.EpochHash,
+		Proof:     proof,
+	}, nil
+}
+
+// VerifyShareAck verify ack against own key share data of the same epoch
+func VerifyShareAck(ack *ShareAck, keyData *tss.KeyStep3Data) bool {
+	if ack == nil || keyData == nil {
+		return false
+	}
+	if ack.Epoch != keyData.Epoch || ack.EpochHash != keyData.EpochHash {
+		return false
+	}
+	X, ok := keyData.SharePubKeyMap[ack.Id]
+	if !ok {
+		return false
+	}
+	sessionId, err := ackSessionId(ack.Epoch, ack.EpochHash)
+	if err != nil {
+		return false
+	}
+	return schnorr.VerifyWithId(sessionId, ack.Proof, X)
+}
+
+func ackSessionId(epoch int, epochHash string) (*big.Int, error) {
+	hash, err := hex.DecodeString(epochHash)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.SHA256Int(ackLabel, big.NewInt(int64(epoch)), new(big.Int).SetBytes(hash)), nil
+}
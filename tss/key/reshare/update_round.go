@@ -21,6 +21,9 @@ type RefreshInfo struct {
 	ui         *big.Int
 	shareI     *big.Int
 	publicKey  *curves.ECPoint
+	epoch      int    // epoch of the old key share
+	epochHash  string // epoch hash of the old key share
+	chaincode  string // unchanged by refresh, committed by the contributors and checked by all devices
 
 	verifiers     []*curves.ECPoint
 	secretShares  []*vss.Share
@@ -53,6 +56,24 @@ func NewRefresh(deviceNumber, total int, devoteList [2]int, ShareI *big.Int, Pub
 	return info
 }
 
+// SetEpoch epoch and epoch hash of the old key share, KeyStep3Data.Epoch and KeyStep3Data.EpochHash
+func (info *RefreshInfo) SetEpoch(epoch int, epochHash string) *RefreshInfo {
+	info.epoch = epoch
+	info.epochHash = epochHash
+	return info
+}
+
+// SetChainCode chaincode of the old key share, KeyStep3Data.ChainCode
+// a device that lost its data leaves it empty and adopts the chaincode committed by the contributors
+func (info *RefreshInfo) SetChainCode(chaincode string) *RefreshInfo {
+	info.chaincode = chaincode
+	return info
+}
+
+func (info *RefreshInfo) isContributor(id int) bool {
+	return id == info.devoteList[0] || id == info.devoteList[1]
+}
+
 func (info *RefreshInfo) Ids() []int {
 	var ids []int
 	for i := 1; i <= info.Total; i++ {
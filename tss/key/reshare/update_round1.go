@@ -25,8 +25,16 @@ func (info *RefreshInfo) DKGStep1() (map[int]*tss.Message, error) {
 		return nil, err
 	}
 
-	// compute verifiers commitment， no chaincode
-	var input []*big.Int
+	// compute chaincode and verifiers commitment, only the contributors commit the chaincode,
+	// a device that lost its data has none and commits 0
+	chaincode := big.NewInt(0)
+	if info.isContributor(info.DeviceNumber) {
+		chaincode, err = chaincodeToInt(info.chaincode)
+		if err != nil {
+			return nil, err
+		}
+	}
+	input := []*big.Int{chaincode}
 	for i := 0; i < len(verifiers); i++ {
 		input = append(input, verifiers[i].X, verifiers[i].Y)
 	}
@@ -42,7 +50,7 @@ func (info *RefreshInfo) DKGStep1() (map[int]*tss.Message, error) {
 		if id == info.DeviceNumber {
 			continue
 		}
-		content := tss.KeyStep1Data{C: &hashCommitment.C}
+		content := tss.KeyStep1Data{C: &hashCommitment.C, Epoch: info.epoch}
 		bytes, err := json.Marshal(content)
 		if err != nil {
 			return nil, err
@@ -27,6 +27,10 @@ func (info *RefreshInfo) DKGStep2(msgs []*tss.Message) (map[int]*tss.Message, er
 		if err != nil {
 			return nil, err
 		}
+		err = tss.CheckEpoch(info.epoch, content.Epoch)
+		if err != nil {
+			return nil, err
+		}
 		info.commitmentMap[msg.From] = *content.C
 	}
 
@@ -46,6 +50,7 @@ func (info *RefreshInfo) DKGStep2(msgs []*tss.Message) (map[int]*tss.Message, er
 			Witness: info.deC,
 			Share:   info.secretShares[id-1],
 			Proof:   proof,
+			Epoch:   info.epoch,
 		}
 		bytes, err := json.Marshal(content)
 		if err != nil {
@@ -13,7 +13,7 @@ import (
 	"github.com/okx/threshold-lib/tss/key/dkg"
 )
 
-// DKGStep3 return new key share information except chaincode
+// DKGStep3 return new key share information, chaincode is checked unchanged
 func (info *RefreshInfo) DKGStep3(msgs []*tss.Message) (*tss.KeyStep3Data, error) {
 	if info.RoundNumber != 3 {
 		return nil, fmt.Errorf("round error")
@@ -30,6 +30,7 @@ func (info *RefreshInfo) DKGStep3(msgs []*tss.Message) (*tss.KeyStep3Data, error
 
 	verifiers := make(map[int][]*curves.ECPoint, len(msgs))
 	verifiers[info.DeviceNumber] = info.verifiers
+	chaincodes := make(map[int]*big.Int, len(msgs))
 	xi := info.secretShares[info.DeviceNumber-1]
 	for _, msg := range msgs {
 		if msg.To != info.DeviceNumber {
@@ -40,15 +41,27 @@ func (info *RefreshInfo) DKGStep3(msgs []*tss.Message) (*tss.KeyStep3Data, error
 		if err != nil {
 			return nil, err
 		}
+		err = tss.CheckEpoch(info.epoch, content.Epoch)
+		if err != nil {
+			return nil, err
+		}
 		hashCommit := commitment.HashCommitment{}
 		hashCommit.C = info.commitmentMap[msg.From]
 		hashCommit.Msg = *content.Witness
 		ok, D := hashCommit.Open()
-		if !ok {
+		if !ok || len(D) == 0 {
 			return nil, fmt.Errorf("commitment DeCommit fail")
 		}
 
-		verifiers[msg.From], err = dkg.UnmarshalVerifiers(curve, D, info.Threshold)
+		if info.isContributor(msg.From) {
+			chaincodes[msg.From] = D[0]
+		} else if D[0].Sign() != 0 {
+			return nil, fmt.Errorf("chaincode committed by participant %d, not a contributor", msg.From)
+		}
+		verifiers[msg.From], err = dkg.UnmarshalVerifiers(curve, D[1:], info.Threshold)
+		if err != nil {
+			return nil, err
+		}
 		if ok, err := feldman.Verify(content.Share, verifiers[msg.From]); !ok {
 			if err != nil {
 				return nil, err
@@ -109,6 +122,16 @@ func (info *RefreshInfo) DKGStep3(msgs []*tss.Message) (*tss.KeyStep3Data, error
 		return nil, fmt.Errorf("public key recalculation error")
 	}
 
+	// contributors must commit the same chaincode, equal to own chaincode if it is set
+	ownChaincode, err := chaincodeToInt(info.chaincode)
+	if err != nil {
+		return nil, err
+	}
+	chaincode, err := agreeChaincode(ownChaincode, chaincodes)
+	if err != nil {
+		return nil, err
+	}
+
 	info.shareI = xi.Y
 	info.publicKey = v[0]
 
@@ -116,7 +139,10 @@ func (info *RefreshInfo) DKGStep3(msgs []*tss.Message) (*tss.KeyStep3Data, error
 		Id:             info.DeviceNumber,
 		ShareI:         info.shareI,
 		PublicKey:      info.publicKey,
+		ChainCode:      chaincode,
 		SharePubKeyMap: sharePubKeyMap,
+		Epoch:          info.epoch + 1,
+		EpochHash:      tss.EpochHash(info.epochHash, info.epoch+1, info.publicKey, sharePubKeyMap),
 	}
 	return content, nil
 }
@@ -2,11 +2,17 @@ package reshare
 
 import (
 	"crypto/elliptic"
+	"errors"
 	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
 	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/vss"
 	"github.com/okx/threshold-lib/tss"
 	"github.com/okx/threshold-lib/tss/key/dkg"
-	"testing"
 )
 
 func TestRefresh(t *testing.T) {
@@ -46,6 +52,306 @@ func TestRefresh(t *testing.T) {
 
 }
 
+func TestRefreshEpoch(t *testing.T) {
+	curve := secp256k1.S256()
+	p1Data, p2Data, p3Data := KeyGen(curve)
+	if p1Data.Epoch != 0 || p1Data.EpochHash != p3Data.EpochHash {
+		t.Fatal("dkg epoch error")
+	}
+	devoteList := [2]int{1, 3}
+	refresh1 := NewRefresh(1, 3, devoteList, p1Data.ShareI, p1Data.PublicKey).SetEpoch(p1Data.Epoch, p1Data.EpochHash).SetChainCode(p1Data.ChainCode)
+	refresh2 := NewRefresh(2, 3, devoteList, nil, p2Data.PublicKey).SetEpoch(p2Data.Epoch, p2Data.EpochHash).SetChainCode(p2Data.ChainCode)
+	refresh3 := NewRefresh(3, 3, devoteList, p3Data.ShareI, p3Data.PublicKey).SetEpoch(p3Data.Epoch, p3Data.EpochHash).SetChainCode(p3Data.ChainCode)
+
+	msgs1_1, _ := refresh1.DKGStep1()
+	msgs2_1, _ := refresh2.DKGStep1()
+	msgs3_1, _ := refresh3.DKGStep1()
+
+	msgs1_2, _ := refresh1.DKGStep2([]*tss.Message{msgs2_1[1], msgs3_1[1]})
+	msgs2_2, _ := refresh2.DKGStep2([]*tss.Message{msgs1_1[2], msgs3_1[2]})
+	msgs3_2, _ := refresh3.DKGStep2([]*tss.Message{msgs1_1[3], msgs2_1[3]})
+
+	p1SaveData, err := refresh1.DKGStep3([]*tss.Message{msgs2_2[1], msgs3_2[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2SaveData, _ := refresh2.DKGStep3([]*tss.Message{msgs1_2[2], msgs3_2[2]})
+	if p1SaveData.Epoch != 1 || p1SaveData.EpochHash != p2SaveData.EpochHash || p1SaveData.EpochHash == p1Data.EpochHash {
+		t.Fatal("refresh epoch error")
+	}
+	if p1SaveData.ChainCode != p1Data.ChainCode || p2SaveData.ChainCode != p1Data.ChainCode {
+		t.Fatal("chaincode changed after refresh")
+	}
+
+	// share deletion acknowledgement
+	ack, err := NewShareAck(p2SaveData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyShareAck(ack, p1SaveData) {
+		t.Fatal("deletion ack verify fail")
+	}
+	if VerifyShareAck(ack, p1Data) {
+		t.Fatal("deletion ack of another epoch should fail")
+	}
+
+	// old epoch share refuses to mix with new epoch share
+	refresh1 = NewRefresh(1, 3, devoteList, p1SaveData.ShareI, p1SaveData.PublicKey).SetEpoch(p1SaveData.Epoch, p1SaveData.EpochHash)
+	refresh3 = NewRefresh(3, 3, devoteList, p3Data.ShareI, p3Data.PublicKey).SetEpoch(p3Data.Epoch, p3Data.EpochHash)
+	msgs1_1, _ = refresh1.DKGStep1()
+	msgs3_1, _ = refresh3.DKGStep1()
+	refresh2 = NewRefresh(2, 3, devoteList, nil, p2Data.PublicKey).SetEpoch(p2SaveData.Epoch, p2SaveData.EpochHash)
+	msgs2_1, _ = refresh2.DKGStep1()
+	if _, err = refresh1.DKGStep2([]*tss.Message{msgs2_1[1], msgs3_1[1]}); err == nil {
+		t.Fatal("mixing epochs should fail")
+	}
+}
+
+func TestRefreshChainCode(t *testing.T) {
+	curve := secp256k1.S256()
+	p1Data, p2Data, p3Data := KeyGen(curve)
+	devoteList := [2]int{1, 3}
+	refresh1 := NewRefresh(1, 3, devoteList, p1Data.ShareI, p1Data.PublicKey).SetChainCode(p1Data.ChainCode)
+	refresh2 := NewRefresh(2, 3, devoteList, nil, p2Data.PublicKey).SetChainCode(p2Data.ChainCode)
+	// device 3 holds a different chaincode
+	refresh3 := NewRefresh(3, 3, devoteList, p3Data.ShareI, p3Data.PublicKey).SetChainCode("00" + p3Data.ChainCode)
+
+	msgs1_1, _ := refresh1.DKGStep1()
+	msgs2_1, _ := refresh2.DKGStep1()
+	msgs3_1, _ := refresh3.DKGStep1()
+
+	msgs1_2, _ := refresh1.DKGStep2([]*tss.Message{msgs2_1[1], msgs3_1[1]})
+	msgs2_2, _ := refresh2.DKGStep2([]*tss.Message{msgs1_1[2], msgs3_1[2]})
+	msgs3_2, _ := refresh3.DKGStep2([]*tss.Message{msgs1_1[3], msgs2_1[3]})
+
+	if _, err := refresh1.DKGStep3([]*tss.Message{msgs2_2[1], msgs3_2[1]}); err == nil {
+		t.Fatal("chaincode mismatch should fail")
+	}
+	if _, err := refresh3.DKGStep3([]*tss.Message{msgs1_2[3], msgs2_2[3]}); err == nil {
+		t.Fatal("chaincode mismatch should fail")
+	}
+}
+
+func TestRefreshLostData(t *testing.T) {
+	curve := secp256k1.S256()
+	p1Data, p2Data, p3Data := KeyGen(curve)
+	devoteList := [2]int{1, 3}
+	// device 2 lost its data, only the public key is known
+	refresh1 := NewRefresh(1, 3, devoteList, p1Data.ShareI, p1Data.PublicKey).SetChainCode(p1Data.ChainCode)
+	refresh2 := NewRefresh(2, 3, devoteList, nil, p2Data.PublicKey)
+	refresh3 := NewRefresh(3, 3, devoteList, p3Data.ShareI, p3Data.PublicKey).SetChainCode(p3Data.ChainCode)
+
+	msgs1_1, _ := refresh1.DKGStep1()
+	msgs2_1, _ := refresh2.DKGStep1()
+	msgs3_1, _ := refresh3.DKGStep1()
+
+	msgs1_2, _ := refresh1.DKGStep2([]*tss.Message{msgs2_1[1], msgs3_1[1]})
+	msgs2_2, _ := refresh2.DKGStep2([]*tss.Message{msgs1_1[2], msgs3_1[2]})
+	msgs3_2, _ := refresh3.DKGStep2([]*tss.Message{msgs1_1[3], msgs2_1[3]})
+
+	p1SaveData, err := refresh1.DKGStep3([]*tss.Message{msgs2_2[1], msgs3_2[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2SaveData, err := refresh2.DKGStep3([]*tss.Message{msgs1_2[2], msgs3_2[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p3SaveData, err := refresh3.DKGStep3([]*tss.Message{msgs1_2[3], msgs2_2[3]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, saveData := range []*tss.KeyStep3Data{p1SaveData, p2SaveData, p3SaveData} {
+		if saveData.ChainCode != p1Data.ChainCode {
+			t.Fatal("chaincode after refresh error", saveData.Id)
+		}
+	}
+}
+
+func TestReshare(t *testing.T) {
+	curve := secp256k1.S256()
+	p1Data, p2Data, p3Data := KeyGen(curve)
+	secret := vss.RecoverSecret(curve, []*vss.Share{
+		{Id: big.NewInt(1), Y: p1Data.ShareI},
+		{Id: big.NewInt(3), Y: p3Data.ShareI},
+	})
+	shares := map[int]*big.Int{1: p1Data.ShareI, 2: p2Data.ShareI, 3: p3Data.ShareI}
+
+	// 2/3 --> 3/5, overlapping members
+	oldPartList := []int{1, 3}
+	newPartList := []int{1, 2, 3, 4, 5}
+	saveData := reshare(t, 3, oldPartList, newPartList, shares, p2Data)
+	checkSecret(t, curve, secret, saveData, []int{2, 4, 5})
+	checkSecret(t, curve, secret, saveData, []int{1, 3, 5})
+
+	// 2/3 --> 2/3, disjoint members
+	newPartList = []int{6, 7, 8}
+	saveData = reshare(t, 2, []int{2, 3}, newPartList, shares, p2Data)
+	checkSecret(t, curve, secret, saveData, []int{6, 8})
+}
+
+func TestJoin(t *testing.T) {
+	curve := secp256k1.S256()
+	p1Data, p2Data, _ := KeyGen(curve)
+	secret := vss.RecoverSecret(curve, []*vss.Share{
+		{Id: big.NewInt(1), Y: p1Data.ShareI},
+		{Id: big.NewInt(2), Y: p2Data.ShareI},
+	})
+	// device 4 joins without any share, device 3 is removed
+	oldPartList := []int{1, 2}
+	newPartList := []int{1, 2, 4}
+	infos := map[int]*ReshareInfo{
+		1: NewReshare(1, 2, oldPartList, newPartList, p1Data.ShareI, p1Data.PublicKey, p1Data.SharePubKeyMap, p1Data.ChainCode),
+		2: NewReshare(2, 2, oldPartList, newPartList, p2Data.ShareI, p2Data.PublicKey, p2Data.SharePubKeyMap, p2Data.ChainCode),
+		4: NewJoin(4, 2, oldPartList, newPartList, p1Data.PublicKey, p1Data.SharePubKeyMap),
+	}
+	saveData, err := runReshare(infos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := saveData[4].SharePubKeyMap[4]; !ok {
+		t.Fatal("share public key map should include joined device")
+	}
+	if _, ok := saveData[4].SharePubKeyMap[3]; ok {
+		t.Fatal("share public key map should not include removed device")
+	}
+	if saveData[4].ChainCode != p1Data.ChainCode {
+		t.Fatal("joined device should receive the chaincode")
+	}
+	if removed := infos[1].RemovedList(); len(removed) != 1 || removed[0] != 3 {
+		t.Fatal("removed list error", removed)
+	}
+	checkSecret(t, curve, secret, saveData, []int{2, 4})
+	checkSecret(t, curve, secret, saveData, []int{1, 4})
+
+	// dealer 1 is removed, the joining device sees every removed device of the old SharePubKeyMap
+	newPartList = []int{2, 4}
+	join := NewJoin(4, 2, oldPartList, newPartList, p1Data.PublicKey, p1Data.SharePubKeyMap)
+	if removed := join.RemovedList(); len(removed) != 2 || removed[0] != 1 || removed[1] != 3 {
+		t.Fatal("joined device removed list error", removed)
+	}
+	info := NewReshare(2, 2, oldPartList, newPartList, p2Data.ShareI, p2Data.PublicKey, p2Data.SharePubKeyMap, p2Data.ChainCode)
+	if removed := info.RemovedList(); len(removed) != 2 || removed[0] != 1 || removed[1] != 3 {
+		t.Fatal("removed list error", removed)
+	}
+
+	// dealer 2 deals with the share of device 1, the joining devices identify it
+	newPartList = []int{4, 5}
+	infos = map[int]*ReshareInfo{
+		1: NewReshare(1, 2, oldPartList, newPartList, p1Data.ShareI, p1Data.PublicKey, p1Data.SharePubKeyMap, p1Data.ChainCode),
+		2: NewReshare(2, 2, oldPartList, newPartList, p1Data.ShareI, p2Data.PublicKey, p2Data.SharePubKeyMap, p2Data.ChainCode),
+		4: NewJoin(4, 2, oldPartList, newPartList, p1Data.PublicKey, p1Data.SharePubKeyMap),
+		5: NewJoin(5, 2, oldPartList, newPartList, p1Data.PublicKey, p1Data.SharePubKeyMap),
+	}
+	_, err = runReshare(infos)
+	var dealerErr *tss.InvalidDealerError
+	if !errors.As(err, &dealerErr) || len(dealerErr.Ids) != 1 || dealerErr.Ids[0] != 2 {
+		t.Fatal("bad dealer should be reported", err)
+	}
+}
+
+func TestReshareFaulty(t *testing.T) {
+	curve := edwards.Edwards()
+	p1Data, p2Data, _ := KeyGen(curve)
+	// device 3 deals with a wrong share
+	shares := map[int]*big.Int{1: p1Data.ShareI, 2: p2Data.ShareI, 3: p1Data.ShareI}
+	infos := make(map[int]*ReshareInfo)
+	for _, id := range []int{1, 2, 3} {
+		infos[id] = NewReshare(id, 2, []int{1, 3}, []int{1, 2, 3}, shares[id], p2Data.PublicKey, p2Data.SharePubKeyMap, p2Data.ChainCode)
+	}
+	_, err := runReshare(infos)
+	if err == nil {
+		t.Fatal("reshare with wrong share should fail")
+	}
+	fmt.Println(err)
+}
+
+func TestReshareChainCode(t *testing.T) {
+	curve := secp256k1.S256()
+	p1Data, _, _ := KeyGen(curve)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("old holder without chaincode should be refused")
+		}
+	}()
+	NewReshare(1, 2, []int{1, 2}, []int{1, 2, 4}, p1Data.ShareI, p1Data.PublicKey, p1Data.SharePubKeyMap, "")
+}
+
+func reshare(t *testing.T, threshold int, oldPartList, newPartList []int, shares map[int]*big.Int, keyData *tss.KeyStep3Data) map[int]*tss.KeyStep3Data {
+	infos := make(map[int]*ReshareInfo)
+	for _, id := range append(append([]int{}, oldPartList...), newPartList...) {
+		if _, ok := infos[id]; ok {
+			continue
+		}
+		infos[id] = NewReshare(id, threshold, oldPartList, newPartList, shares[id], keyData.PublicKey, keyData.SharePubKeyMap, keyData.ChainCode)
+	}
+	saveData, err := runReshare(infos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range newPartList {
+		if !saveData[id].PublicKey.Equals(keyData.PublicKey) {
+			t.Fatal("public key changed")
+		}
+		if len(saveData[id].SharePubKeyMap) != len(newPartList) {
+			t.Fatal("share public key map error")
+		}
+	}
+	return saveData
+}
+
+// runReshare route p2p messages between devices
+func runReshare(infos map[int]*ReshareInfo) (map[int]*tss.KeyStep3Data, error) {
+	ids := make([]int, 0, len(infos))
+	for id := range infos {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	inbox := make(map[int][]*tss.Message)
+	for _, id := range ids {
+		out, err := infos[id].ReshareStep1()
+		if err != nil {
+			return nil, err
+		}
+		for to, msg := range out {
+			inbox[to] = append(inbox[to], msg)
+		}
+	}
+	inbox2 := make(map[int][]*tss.Message)
+	for _, id := range ids {
+		out, err := infos[id].ReshareStep2(inbox[id])
+		if err != nil {
+			return nil, err
+		}
+		for to, msg := range out {
+			inbox2[to] = append(inbox2[to], msg)
+		}
+	}
+	saveData := make(map[int]*tss.KeyStep3Data)
+	for _, id := range ids {
+		if !infos[id].isReceiver() {
+			continue
+		}
+		data, err := infos[id].ReshareStep3(inbox2[id])
+		if err != nil {
+			return nil, err
+		}
+		saveData[id] = data
+	}
+	return saveData, nil
+}
+
+func checkSecret(t *testing.T, curve elliptic.Curve, secret *big.Int, saveData map[int]*tss.KeyStep3Data, ids []int) {
+	shares := make([]*vss.Share, len(ids))
+	for i, id := range ids {
+		shares[i] = &vss.Share{Id: big.NewInt(int64(id)), Y: saveData[id].ShareI}
+	}
+	if vss.RecoverSecret(curve, shares).Cmp(secret) != 0 {
+		t.Fatal("recover secret error")
+	}
+}
+
 func KeyGen(curve elliptic.Curve) (*tss.KeyStep3Data, *tss.KeyStep3Data, *tss.KeyStep3Data) {
 	setUp1 := dkg.NewSetUp(1, 3, curve)
 	setUp2 := dkg.NewSetUp(2, 3, curve)
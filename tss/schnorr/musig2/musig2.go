@@ -0,0 +1,195 @@
+package musig2
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss/schnorr/sign"
+)
+
+var (
+	curve = secp256k1.S256()
+)
+
+// BIP327 tags
+const (
+	tagKeyAggList  = "KeyAgg list"
+	tagKeyAggCoeff = "KeyAgg coefficient"
+	tagAux         = "MuSig/aux"
+	tagNonce       = "MuSig/nonce"
+	tagNonceCoeff  = "MuSig/noncecoef"
+	tagChallenge   = "BIP0340/challenge"
+)
+
+// KeyAggContext aggregated key Q = sum(a_i*P_i) with the accumulated tweak, BIP327 KeyAgg
+type KeyAggContext struct {
+	Q        *curves.ECPoint
+	gacc     *big.Int // accumulated negation, 1 or n-1
+	tacc     *big.Int // accumulated tweak
+	pubKeys  [][]byte // 33 bytes compressed public keys, in signing order
+	listHash []byte
+	pk2      []byte // second distinct key, coefficient 1
+}
+
+// KeySort sort compressed public keys lexicographically, the order of KeyAgg input matters
+func KeySort(pubKeys [][]byte) [][]byte {
+	sorted := make([][]byte, len(pubKeys))
+	copy(sorted, pubKeys)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+	return sorted
+}
+
+// KeyAgg aggregate 33 bytes compressed public keys, Q = sum(a_i*P_i)
+// a_i = hash_KeyAgg coefficient(L || pk_i), 1 for the second distinct key
+func KeyAgg(pubKeys [][]byte) (*KeyAggContext, error) {
+	if len(pubKeys) == 0 {
+		return nil, fmt.Errorf("public keys are empty")
+	}
+	ctx := &KeyAggContext{
+		gacc:     big.NewInt(1),
+		tacc:     big.NewInt(0),
+		pubKeys:  pubKeys,
+		listHash: sign.TaggedHash(tagKeyAggList, pubKeys...),
+		pk2:      make([]byte, 33),
+	}
+	for _, pk := range pubKeys[1:] {
+		if !bytes.Equal(pk, pubKeys[0]) {
+			ctx.pk2 = pk
+			break
+		}
+	}
+	var Q *curves.ECPoint
+	for i, pk := range pubKeys {
+		P, err := parsePoint(pk)
+		if err != nil {
+			return nil, &InvalidContributionError{Index: i, Err: err}
+		}
+		Q = addPoints(Q, scalarMult(P, ctx.coefficient(pk)))
+	}
+	if Q == nil {
+		return nil, fmt.Errorf("aggregated key is infinity")
+	}
+	ctx.Q = Q
+	return ctx, nil
+}
+
+// ApplyTweak plain tweak Q' = Q + t*G (bip32), or x-only tweak Q' = lift_x(Q) + t*G (taproot)
+func (ctx *KeyAggContext) ApplyTweak(tweak []byte, isXOnly bool) error {
+	if len(tweak) != 32 {
+		return fmt.Errorf("tweak length error")
+	}
+	t := new(big.Int).SetBytes(tweak)
+	if t.Cmp(curve.N) >= 0 {
+		return fmt.Errorf("tweak out of range")
+	}
+	g := big.NewInt(1)
+	if isXOnly {
+		g = negFactor(ctx.Q)
+	}
+	Q := addPoints(scalarMult(ctx.Q, g), scalarMult(nil, t))
+	if Q == nil {
+		return fmt.Errorf("tweaked key is infinity")
+	}
+	ctx.Q = Q
+	ctx.gacc = new(big.Int).Mod(new(big.Int).Mul(g, ctx.gacc), curve.N)
+	ctx.tacc = new(big.Int).Mod(new(big.Int).Add(t, new(big.Int).Mul(g, ctx.tacc)), curve.N)
+	return nil
+}
+
+// XOnlyPubKey 32 bytes BIP340 public key
+func (ctx *KeyAggContext) XOnlyPubKey() []byte {
+	return sign.XOnly(ctx.Q)
+}
+
+// PlainPubKey 33 bytes compressed public key
+func (ctx *KeyAggContext) PlainPubKey() []byte {
+	return serializePoint(ctx.Q)
+}
+
+// coefficient a_i of pk
+func (ctx *KeyAggContext) coefficient(pk []byte) *big.Int {
+	if bytes.Equal(pk, ctx.pk2) {
+		return big.NewInt(1)
+	}
+	a := new(big.Int).SetBytes(sign.TaggedHash(tagKeyAggCoeff, ctx.listHash, pk))
+	return a.Mod(a, curve.N)
+}
+
+// InvalidContributionError the public key or public nonce at Index is invalid
+type InvalidContributionError struct {
+	Index int
+	Err   error
+}
+
+func (e *InvalidContributionError) Error() string {
+	return fmt.Sprintf("invalid contribution of signer %d: %v", e.Index, e.Err)
+}
+
+// parsePoint 33 bytes compressed point
+func parsePoint(b []byte) (*curves.ECPoint, error) {
+	if len(b) != 33 || (b[0] != 2 && b[0] != 3) {
+		return nil, fmt.Errorf("point encoding error")
+	}
+	P, err := sign.LiftX(new(big.Int).SetBytes(b[1:]))
+	if err != nil {
+		return nil, err
+	}
+	if b[0] == 3 {
+		return negate(P), nil
+	}
+	return P, nil
+}
+
+// serializePoint 33 bytes compressed point, 33 zero bytes for infinity
+func serializePoint(p *curves.ECPoint) []byte {
+	b := make([]byte, 33)
+	if p == nil {
+		return b
+	}
+	b[0] = 2 + byte(p.Y.Bit(0))
+	p.X.FillBytes(b[1:])
+	return b
+}
+
+// addPoints nil is the point at infinity
+func addPoints(p1, p2 *curves.ECPoint) *curves.ECPoint {
+	if p1 == nil {
+		return p2
+	}
+	if p2 == nil {
+		return p1
+	}
+	p, err := p1.Add(p2)
+	if err != nil {
+		return nil
+	}
+	return p
+}
+
+// scalarMult k*P, P nil for the generator, nil result is the point at infinity
+func scalarMult(p *curves.ECPoint, k *big.Int) *curves.ECPoint {
+	k = new(big.Int).Mod(k, curve.N)
+	if k.Sign() == 0 {
+		return nil
+	}
+	if p == nil {
+		return curves.ScalarToPoint(curve, k)
+	}
+	return p.ScalarMult(k)
+}
+
+func negate(p *curves.ECPoint) *curves.ECPoint {
+	return &curves.ECPoint{Curve: p.Curve, X: p.X, Y: new(big.Int).Sub(curve.P, p.Y)}
+}
+
+// negFactor 1 if P has even y, otherwise n-1
+func negFactor(p *curves.ECPoint) *big.Int {
+	if p.Y.Bit(0) == 0 {
+		return big.NewInt(1)
+	}
+	return new(big.Int).Sub(curve.N, big.NewInt(1))
+}
@@ -0,0 +1,208 @@
+package musig2
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss/schnorr/sign"
+)
+
+func TestMuSig2(t *testing.T) {
+	message := sha256.Sum256([]byte("hello"))
+	sks := make([]*big.Int, 3)
+	pubKeys := make([][]byte, 3)
+	for i := range sks {
+		sks[i] = crypto.RandomNum(curve.N)
+		pubKeys[i] = serializePoint(curves.ScalarToPoint(curve, sks[i]))
+	}
+
+	for _, tweak := range []struct {
+		tweak   []byte
+		isXOnly bool
+	}{{nil, false}, {message[:], true}, {message[:], false}} {
+		keyAgg, err := KeyAgg(KeySort(pubKeys))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tweak.tweak != nil {
+			if err := keyAgg.ApplyTweak(tweak.tweak, tweak.isXOnly); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		secNonces := make([]*SecNonce, 3)
+		pubNonces := make([]*PubNonce, 3)
+		for i := range sks {
+			secNonces[i], pubNonces[i], err = NonceGen(sks[i], pubKeys[i], keyAgg.XOnlyPubKey(), message[:], nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		aggNonce, err := NonceAgg(pubNonces)
+		if err != nil {
+			t.Fatal(err)
+		}
+		session, err := NewSession(keyAgg, aggNonce, message[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		psigs := make([]*big.Int, 3)
+		for i := range sks {
+			psigs[i], err = session.Sign(secNonces[i], sks[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !session.PartialSigVerify(psigs[i], pubNonces[i], pubKeys[i]) {
+				t.Fatal("partial signature verify fail")
+			}
+		}
+		if session.PartialSigVerify(new(big.Int).Add(psigs[0], big.NewInt(1)), pubNonces[0], pubKeys[0]) {
+			t.Fatal("invalid partial signature accepted")
+		}
+		if _, err := session.Sign(secNonces[0], sks[0]); err == nil {
+			t.Fatal("secret nonce must not be used twice")
+		}
+		signature, err := session.PartialSigAgg(psigs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sign.Verify(keyAgg.XOnlyPubKey(), message[:], signature) {
+			t.Fatal("bip340 signature verify fail")
+		}
+	}
+}
+
+func TestKeyAggVectors(t *testing.T) {
+	pubKeys := [][]byte{
+		hexBytes("02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9"),
+		hexBytes("03DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659"),
+		hexBytes("023590A94E768F8E1815C2F24B4D80A8E3149316C3518CE7B7AD338368D038CA66"),
+		hexBytes("020000000000000000000000000000000000000000000000000000000000000005"),
+	}
+	vectors := []struct {
+		keyIndices []int
+		expected   string
+	}{
+		{[]int{0, 1, 2}, "90539EEDE565F5D054F32CC0C220126889ED1E5D193BAF15AEF344FE59D4610C"},
+		{[]int{2, 1, 0}, "6204DE8B083426DC6EAF9502D27024D53FC826BF7D2012148A0575435DF54B2B"},
+		{[]int{0, 0, 0}, "B436E3BAD62B8CD409969A224731C193D051162D8C5AE8B109306127DA3AA935"},
+		{[]int{0, 0, 1, 1}, "69BC22BFA5D106306E48A20679DE1D7389386124D07571D0D872686028C26A3E"},
+	}
+	for i, v := range vectors {
+		var keys [][]byte
+		for _, k := range v.keyIndices {
+			keys = append(keys, pubKeys[k])
+		}
+		keyAgg, err := KeyAgg(keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(keyAgg.XOnlyPubKey(), hexBytes(v.expected)) {
+			t.Fatal("key agg vector fail", i)
+		}
+	}
+	// 0x05 is not a valid x coordinate
+	_, err := KeyAgg([][]byte{pubKeys[0], pubKeys[3]})
+	contribErr, ok := err.(*InvalidContributionError)
+	if !ok || contribErr.Index != 1 {
+		t.Fatal("invalid public key should be identified", err)
+	}
+}
+
+func TestNonceGenVector(t *testing.T) {
+	secNonce, pubNonce, err := nonceGen(bytes.NewReader(bytes.Repeat([]byte{0x0F}, 32)),
+		new(big.Int).SetBytes(bytes.Repeat([]byte{0x02}, 32)),
+		hexBytes("024D4B6CD1361032CA9BD2AEB9D900AA4D45D9EAD80AC9423374C451A7254D0766"),
+		bytes.Repeat([]byte{0x07}, 32), bytes.Repeat([]byte{0x01}, 32), bytes.Repeat([]byte{0x08}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(secNonce.Bytes(), hexBytes("B114E502BEAA4E301DD08A50264172C84E41650E6CB726B410C0694D59EFFB6495B5CAF28D045B973D63E3C99A44B807BDE375FD6CB39E46DC4A511708D0E9D2024D4B6CD1361032CA9BD2AEB9D900AA4D45D9EAD80AC9423374C451A7254D0766")) {
+		t.Fatal("secret nonce mismatch")
+	}
+	if !bytes.Equal(pubNonce.Bytes(), hexBytes("02F7BE7089E8376EB355272368766B17E88E7DB72047D05E56AA881EA52B3B35DF02C29C8046FDD0DED4C7E55869137200FBDBFE2EB654267B6D7013602CAED3115A")) {
+		t.Fatal("public nonce mismatch")
+	}
+}
+
+func TestSignVectors(t *testing.T) {
+	sk := new(big.Int).SetBytes(hexBytes("7FB9E0E687ADA1EEBF7ECFE2F21E73EBDB51A7D450948DFE8D76D7F2D1007671"))
+	pubKeys := [][]byte{
+		hexBytes("03935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9"),
+		hexBytes("02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9"),
+		hexBytes("02DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA661"),
+	}
+	pubNonces := make([]*PubNonce, 3)
+	for i, s := range []string{
+		"0337C87821AFD50A8644D820A8F3E02E499C931865C2360FB43D0A0D20DAFE07EA0287BF891D2A6DEAEBADC909352AA9405D1428C15F4B75F04DAE642A95C2548480",
+		"0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F817980279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		"032DE2662628C90B03F5E720284EB52FF7D71F4284F627B68A853D78C78E1FFE9303E4C5524E83FFE1493B9077CF1CA6BEB2090C93D930321071AD40B2F44E599046",
+	} {
+		pubNonce, err := ParsePubNonce(hexBytes(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubNonces[i] = pubNonce
+	}
+	aggNonce, err := NonceAgg(pubNonces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(aggNonce.Bytes(), hexBytes("028465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD61037496A3CC86926D452CAFCFD55D25972CA1675D549310DE296BFF42F72EEEA8C9")) {
+		t.Fatal("aggregated nonce mismatch")
+	}
+	message := hexBytes("F95466D086770E689964664219266FE5ED215C92AE20BAB5C9D79ADDDDF3C0CF")
+
+	vectors := []struct {
+		keyIndices []int
+		expected   string
+	}{
+		{[]int{0, 1, 2}, "012ABBCB52B3016AC03AD82395A1A415C48B93DEF78718E62A7A90052FE224FB"},
+		{[]int{1, 0, 2}, "9FF2F7AAA856150CC8819254218D3ADEEB0535269051897724F9DB3789513A52"},
+		{[]int{1, 2, 0}, "FA23C359F6FAC4E7796BB93BC9F0532A95468C539BA20FF86D7C76ED92227900"},
+	}
+	for i, v := range vectors {
+		var keys [][]byte
+		for _, k := range v.keyIndices {
+			keys = append(keys, pubKeys[k])
+		}
+		keyAgg, err := KeyAgg(keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		secNonce, err := ParseSecNonce(hexBytes("508B81A611F100A6B2B6B29656590898AF488BCF2E1F55CF22E5CFB84421FE61FA27FD49B1D50085B481285E1CA205D55C82CC1B31FF5CD54A489829355901F703935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		session, err := NewSession(keyAgg, aggNonce, message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		psig, err := session.Sign(secNonce, sk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(bigIntBytes(psig), hexBytes(v.expected)) {
+			t.Fatal("sign vector fail", i)
+		}
+		if !session.PartialSigVerify(psig, pubNonces[0], pubKeys[0]) {
+			t.Fatal("partial signature verify fail", i)
+		}
+	}
+}
+
+func hexBytes(s string) []byte {
+	b, _ := hex.DecodeString(s)
+	return b
+}
+
+func bigIntBytes(n *big.Int) []byte {
+	b := make([]byte, 32)
+	n.FillBytes(b)
+	return b
+}
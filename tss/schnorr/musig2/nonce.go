@@ -0,0 +1,172 @@
+package musig2
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss/schnorr/sign"
+)
+
+// SecNonce secret nonces k1, k2 of one signing session, cleared by Sign
+type SecNonce struct {
+	k1, k2 *big.Int
+	pk     []byte // public key of the signer
+}
+
+// PubNonce R1 = k1*G, R2 = k2*G, sent to the other signers
+type PubNonce struct {
+	R1, R2 *curves.ECPoint
+}
+
+// AggNonce sum of the public nonces, R1 or R2 is nil if the sum is infinity
+type AggNonce struct {
+	R1, R2 *curves.ECPoint
+}
+
+// NonceGen BIP327 nonce generation, only pk is required
+// sk, aggPk, msg and extraIn are optional and harden the nonce against a bad random number generator
+func NonceGen(sk *big.Int, pk, aggPk, msg, extraIn []byte) (*SecNonce, *PubNonce, error) {
+	return nonceGen(cryptorand.Reader, sk, pk, aggPk, msg, extraIn)
+}
+
+func nonceGen(rand io.Reader, sk *big.Int, pk, aggPk, msg, extraIn []byte) (*SecNonce, *PubNonce, error) {
+	if len(pk) != 33 {
+		return nil, nil, fmt.Errorf("public key length error")
+	}
+	if len(aggPk) != 0 && len(aggPk) != 32 {
+		return nil, nil, fmt.Errorf("aggregated public key length error")
+	}
+	random := make([]byte, 32)
+	_, err := io.ReadFull(rand, random)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sk != nil {
+		skBytes := make([]byte, 32)
+		sk.FillBytes(skBytes)
+		aux := sign.TaggedHash(tagAux, random)
+		for i := range random {
+			random[i] = skBytes[i] ^ aux[i]
+		}
+	}
+	var msgPrefixed []byte
+	if msg == nil {
+		msgPrefixed = []byte{0}
+	} else {
+		msgPrefixed = make([]byte, 9, 9+len(msg))
+		msgPrefixed[0] = 1
+		binary.BigEndian.PutUint64(msgPrefixed[1:], uint64(len(msg)))
+		msgPrefixed = append(msgPrefixed, msg...)
+	}
+	var extraLen [4]byte
+	binary.BigEndian.PutUint32(extraLen[:], uint32(len(extraIn)))
+
+	k := make([]*big.Int, 2)
+	for i := range k {
+		h := sign.TaggedHash(tagNonce, random, []byte{byte(len(pk))}, pk, []byte{byte(len(aggPk))}, aggPk,
+			msgPrefixed, extraLen[:], extraIn, []byte{byte(i)})
+		k[i] = new(big.Int).Mod(new(big.Int).SetBytes(h), curve.N)
+		if k[i].Sign() == 0 {
+			return nil, nil, fmt.Errorf("nonce is zero")
+		}
+	}
+	secNonce := &SecNonce{k1: k[0], k2: k[1], pk: pk}
+	pubNonce := &PubNonce{R1: curves.ScalarToPoint(curve, k[0]), R2: curves.ScalarToPoint(curve, k[1])}
+	return secNonce, pubNonce, nil
+}
+
+// NonceAgg R1 = sum(R1_i), R2 = sum(R2_i)
+func NonceAgg(pubNonces []*PubNonce) (*AggNonce, error) {
+	if len(pubNonces) == 0 {
+		return nil, fmt.Errorf("public nonces are empty")
+	}
+	aggNonce := &AggNonce{}
+	for i, pubNonce := range pubNonces {
+		if pubNonce == nil || !validPoint(pubNonce.R1) || !validPoint(pubNonce.R2) {
+			return nil, &InvalidContributionError{Index: i, Err: fmt.Errorf("invalid public nonce")}
+		}
+		aggNonce.R1 = addPoints(aggNonce.R1, pubNonce.R1)
+		aggNonce.R2 = addPoints(aggNonce.R2, pubNonce.R2)
+	}
+	return aggNonce, nil
+}
+
+// Bytes 66 bytes cbytes(R1) || cbytes(R2)
+func (n *PubNonce) Bytes() []byte {
+	return append(serializePoint(n.R1), serializePoint(n.R2)...)
+}
+
+// ParsePubNonce 66 bytes public nonce
+func ParsePubNonce(b []byte) (*PubNonce, error) {
+	if len(b) != 66 {
+		return nil, fmt.Errorf("public nonce length error")
+	}
+	R1, err := parsePoint(b[:33])
+	if err != nil {
+		return nil, err
+	}
+	R2, err := parsePoint(b[33:])
+	if err != nil {
+		return nil, err
+	}
+	return &PubNonce{R1: R1, R2: R2}, nil
+}
+
+// Bytes 66 bytes aggregated nonce, infinity is encoded as 33 zero bytes
+func (n *AggNonce) Bytes() []byte {
+	return append(serializePoint(n.R1), serializePoint(n.R2)...)
+}
+
+// ParseAggNonce 66 bytes aggregated nonce
+func ParseAggNonce(b []byte) (*AggNonce, error) {
+	if len(b) != 66 {
+		return nil, fmt.Errorf("aggregated nonce length error")
+	}
+	R1, err := parsePointExt(b[:33])
+	if err != nil {
+		return nil, err
+	}
+	R2, err := parsePointExt(b[33:])
+	if err != nil {
+		return nil, err
+	}
+	return &AggNonce{R1: R1, R2: R2}, nil
+}
+
+// Bytes 97 bytes k1 || k2 || pk, compatible with other BIP327 implementations
+func (n *SecNonce) Bytes() []byte {
+	b := make([]byte, 64, 97)
+	if n.k1 != nil && n.k2 != nil {
+		n.k1.FillBytes(b[:32])
+		n.k2.FillBytes(b[32:64])
+	}
+	return append(b, n.pk...)
+}
+
+// ParseSecNonce 97 bytes secret nonce
+func ParseSecNonce(b []byte) (*SecNonce, error) {
+	if len(b) != 97 {
+		return nil, fmt.Errorf("secret nonce length error")
+	}
+	pk := make([]byte, 33)
+	copy(pk, b[64:])
+	return &SecNonce{k1: new(big.Int).SetBytes(b[:32]), k2: new(big.Int).SetBytes(b[32:64]), pk: pk}, nil
+}
+
+// parsePointExt parsePoint, 33 zero bytes is the point at infinity
+func parsePointExt(b []byte) (*curves.ECPoint, error) {
+	for _, v := range b {
+		if v != 0 {
+			return parsePoint(b)
+		}
+	}
+	return nil, nil
+}
+
+func validPoint(p *curves.ECPoint) bool {
+	return p != nil && p.X != nil && p.Y != nil && curve.IsOnCurve(p.X, p.Y)
+}
@@ -0,0 +1,135 @@
+package musig2
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss/schnorr/sign"
+)
+
+// Session values shared by all signers of one message, BIP327 GetSessionValues
+type Session struct {
+	keyAgg *KeyAggContext
+	msg    []byte
+	b      *big.Int        // nonce coefficient
+	R      *curves.ECPoint // final nonce R = R1 + b*R2
+	e      *big.Int        // BIP340 challenge
+}
+
+// NewSession b = hash_MuSig/noncecoef(aggnonce || xonly(Q) || m), R = R1 + b*R2, e = hash_BIP0340/challenge(xonly(R) || xonly(Q) || m)
+func NewSession(keyAgg *KeyAggContext, aggNonce *AggNonce, msg []byte) (*Session, error) {
+	if keyAgg == nil || aggNonce == nil {
+		return nil, fmt.Errorf("session parameters error")
+	}
+	Q := keyAgg.XOnlyPubKey()
+	b := new(big.Int).SetBytes(sign.TaggedHash(tagNonceCoeff, aggNonce.Bytes(), Q, msg))
+	b.Mod(b, curve.N)
+	R := addPoints(aggNonce.R1, scalarMult(aggNonce.R2, b))
+	if R == nil {
+		R = curves.ScalarToPoint(curve, big.NewInt(1))
+	}
+	e := new(big.Int).SetBytes(sign.TaggedHash(tagChallenge, sign.XOnly(R), Q, msg))
+	e.Mod(e, curve.N)
+	return &Session{keyAgg: keyAgg, msg: msg, b: b, R: R, e: e}, nil
+}
+
+// Sign partial signature s = k1 + b*k2 + e*a*d, secNonce is cleared and can not sign again
+// k1, k2 are negated if R has odd y, d = g*gacc*sk with g negating Q to even y
+func (s *Session) Sign(secNonce *SecNonce, sk *big.Int) (*big.Int, error) {
+	if secNonce == nil || secNonce.k1 == nil || secNonce.k2 == nil {
+		return nil, fmt.Errorf("secret nonce is already used")
+	}
+	k1, k2 := secNonce.k1, secNonce.k2
+	secNonce.k1, secNonce.k2 = nil, nil
+	if k1.Sign() <= 0 || k1.Cmp(curve.N) >= 0 || k2.Sign() <= 0 || k2.Cmp(curve.N) >= 0 {
+		return nil, fmt.Errorf("secret nonce out of range")
+	}
+	if sk == nil || sk.Sign() <= 0 || sk.Cmp(curve.N) >= 0 {
+		return nil, fmt.Errorf("secret key out of range")
+	}
+	P := curves.ScalarToPoint(curve, sk)
+	pk := serializePoint(P)
+	if !bytes.Equal(pk, secNonce.pk) {
+		return nil, fmt.Errorf("public key does not match nonce")
+	}
+	if !s.contains(pk) {
+		return nil, fmt.Errorf("public key is not in the aggregated keys")
+	}
+	gR := negFactor(s.R)
+	k1 = new(big.Int).Mul(k1, gR)
+	k2 = new(big.Int).Mul(k2, gR)
+	d := new(big.Int).Mul(sk, s.keyFactor())
+
+	// s = k1 + b*k2 + e*a*d
+	si := new(big.Int).Mul(s.b, k2)
+	si.Add(si, k1)
+	si.Add(si, new(big.Int).Mul(new(big.Int).Mul(s.e, s.keyAgg.coefficient(pk)), d))
+	si.Mod(si, curve.N)
+	return si, nil
+}
+
+// PartialSigVerify s*G == gR*(R1 + b*R2) + e*a*g*gacc*P
+func (s *Session) PartialSigVerify(psig *big.Int, pubNonce *PubNonce, pk []byte) bool {
+	if psig == nil || psig.Sign() < 0 || psig.Cmp(curve.N) >= 0 || pubNonce == nil {
+		return false
+	}
+	if !validPoint(pubNonce.R1) || !validPoint(pubNonce.R2) || !s.contains(pk) {
+		return false
+	}
+	P, err := parsePoint(pk)
+	if err != nil {
+		return false
+	}
+	Re := addPoints(pubNonce.R1, scalarMult(pubNonce.R2, s.b))
+	if Re != nil {
+		Re = scalarMult(Re, negFactor(s.R))
+	}
+	ea := new(big.Int).Mul(s.e, s.keyAgg.coefficient(pk))
+	right := addPoints(Re, scalarMult(P, ea.Mul(ea, s.keyFactor())))
+	left := scalarMult(nil, psig)
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	return left.Equals(right)
+}
+
+// PartialSigAgg s = sum(s_i) + e*g*tacc, returns the BIP340 signature xonly(R) || s, verified against the aggregated key
+func (s *Session) PartialSigAgg(psigs []*big.Int) ([]byte, error) {
+	if len(psigs) == 0 {
+		return nil, fmt.Errorf("partial signatures are empty")
+	}
+	sum := new(big.Int)
+	for i, psig := range psigs {
+		if psig == nil || psig.Sign() < 0 || psig.Cmp(curve.N) >= 0 {
+			return nil, &InvalidContributionError{Index: i, Err: fmt.Errorf("partial signature out of range")}
+		}
+		sum.Add(sum, psig)
+	}
+	tweak := new(big.Int).Mul(s.e, negFactor(s.keyAgg.Q))
+	sum.Add(sum, tweak.Mul(tweak, s.keyAgg.tacc))
+	sum.Mod(sum, curve.N)
+
+	signature := make([]byte, 64)
+	s.R.X.FillBytes(signature[:32])
+	sum.FillBytes(signature[32:])
+	if !sign.Verify(s.keyAgg.XOnlyPubKey(), s.msg, signature) {
+		return nil, fmt.Errorf("aggregated signature verify fail")
+	}
+	return signature, nil
+}
+
+// keyFactor g*gacc, g negates Q to even y
+func (s *Session) keyFactor() *big.Int {
+	return new(big.Int).Mod(new(big.Int).Mul(negFactor(s.keyAgg.Q), s.keyAgg.gacc), curve.N)
+}
+
+func (s *Session) contains(pk []byte) bool {
+	for _, key := range s.keyAgg.pubKeys {
+		if bytes.Equal(key, pk) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package sign
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+)
+
+// SetSharePubKeyMap ShareI*G map from KeyStep3Data, required to verify partial signatures
+func (bip340 *SchnorrSign) SetSharePubKeyMap(sharePubKeyMap map[int]*curves.ECPoint) *SchnorrSign {
+	bip340.Signer.SetSharePubKeyMap(sharePubKeyMap)
+	return bip340
+}
+
+// encode BIP340 signature xonly(R) || s
+func (bip340 *SchnorrSign) encode(R *curves.ECPoint, s *big.Int) ([]byte, error) {
+	signature := make([]byte, 64)
+	R.X.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signature, nil
+}
+
+// verify against OutputKey
+func (bip340 *SchnorrSign) verify(signature []byte) bool {
+	bytes, err := hex.DecodeString(bip340.Message())
+	if err != nil {
+		return false
+	}
+	return Verify(XOnly(bip340.OutputKey), bytes, signature)
+}
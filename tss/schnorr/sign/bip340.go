@@ -0,0 +1,125 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+)
+
+// BIP340 / BIP341 tags
+const (
+	tagChallenge = "BIP0340/challenge"
+	tagTapTweak  = "TapTweak"
+)
+
+// TaggedHash sha256(sha256(tag) || sha256(tag) || msg)
+func TaggedHash(tag string, msgs ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, m := range msgs {
+		h.Write(m)
+	}
+	return h.Sum(nil)
+}
+
+// XOnly 32 bytes x coordinate of the point
+func XOnly(p *curves.ECPoint) []byte {
+	x := make([]byte, 32)
+	p.X.FillBytes(x)
+	return x
+}
+
+// TaprootOutputKey Q = lift_x(P) + t*G, t = hashTapTweak(xonly(P) || merkleRoot)
+// merkleRoot is empty for a key path only output (BIP86), otherwise the 32 bytes script tree root
+func TaprootOutputKey(publicKey *curves.ECPoint, merkleRoot []byte) (*curves.ECPoint, error) {
+	Q, _, err := taprootTweak(publicKey, merkleRoot)
+	return Q, err
+}
+
+// taprootTweak returns the output key with its original y and the tweak t
+func taprootTweak(publicKey *curves.ECPoint, merkleRoot []byte) (*curves.ECPoint, *big.Int, error) {
+	if publicKey == nil {
+		return nil, nil, fmt.Errorf("public key is nil")
+	}
+	if len(merkleRoot) != 0 && len(merkleRoot) != 32 {
+		return nil, nil, fmt.Errorf("merkle root length error")
+	}
+	t := new(big.Int).SetBytes(TaggedHash(tagTapTweak, XOnly(publicKey), merkleRoot))
+	if t.Cmp(curve.N) >= 0 {
+		return nil, nil, fmt.Errorf("tweak out of range")
+	}
+	Q, err := evenY(publicKey).Add(curves.ScalarToPoint(curve, t))
+	if err != nil {
+		return nil, nil, err
+	}
+	return Q, t, nil
+}
+
+// Verify BIP340 signature, publicKey is the 32 bytes x-only key
+func Verify(publicKey, message, signature []byte) bool {
+	if len(publicKey) != 32 || len(signature) != 64 {
+		return false
+	}
+	P, err := LiftX(new(big.Int).SetBytes(publicKey))
+	if err != nil {
+		return false
+	}
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if r.Cmp(curve.P) >= 0 || s.Cmp(curve.N) >= 0 {
+		return false
+	}
+	e := challenge(signature[:32], publicKey, message)
+
+	// R = s*G - e*P
+	R, err := curves.ScalarToPoint(curve, s).Add(P.ScalarMult(new(big.Int).Sub(curve.N, e)))
+	if err != nil {
+		return false
+	}
+	return R.Y.Bit(0) == 0 && R.X.Cmp(r) == 0
+}
+
+// challenge e = int(hashBIP0340/challenge(xonly(R) || xonly(P) || m)) mod n
+func challenge(R, publicKey, message []byte) *big.Int {
+	e := new(big.Int).SetBytes(TaggedHash(tagChallenge, R, publicKey, message))
+	return e.Mod(e, curve.N)
+}
+
+// LiftX the point with x coordinate x and even y
+func LiftX(x *big.Int) (*curves.ECPoint, error) {
+	if x.Sign() <= 0 || x.Cmp(curve.P) >= 0 {
+		return nil, fmt.Errorf("x out of range")
+	}
+	// y^2 = x^3 + 7
+	c := new(big.Int).Exp(x, big.NewInt(3), curve.P)
+	c.Add(c, big.NewInt(7))
+	c.Mod(c, curve.P)
+	y := new(big.Int).ModSqrt(c, curve.P)
+	if y == nil {
+		return nil, fmt.Errorf("x is not on the curve")
+	}
+	if y.Bit(0) == 1 {
+		y.Sub(curve.P, y)
+	}
+	return curves.NewECPoint(curve, x, y)
+}
+
+// evenY P if P has even y, otherwise -P
+func evenY(p *curves.ECPoint) *curves.ECPoint {
+	if p.Y.Bit(0) == 0 {
+		return p
+	}
+	return &curves.ECPoint{Curve: p.Curve, X: p.X, Y: new(big.Int).Sub(curve.P, p.Y)}
+}
+
+// negFactor 1 if P has even y, otherwise n-1
+func negFactor(p *curves.ECPoint) *big.Int {
+	if p.Y.Bit(0) == 0 {
+		return big.NewInt(1)
+	}
+	return new(big.Int).Sub(curve.N, big.NewInt(1))
+}
@@ -0,0 +1,19 @@
+package sign
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+)
+
+// challenge e = hashBIP0340/challenge(xonly(R) || xonly(OutputKey) || m) and r, the x coordinate of R
+// SignStep3 returns si = gR*ki + e*wi, gR negates the nonces when R has odd y
+func (bip340 *SchnorrSign) challenge(R *curves.ECPoint) (*big.Int, *big.Int, error) {
+	bytes, err := hex.DecodeString(bip340.Message())
+	if err != nil {
+		return nil, nil, err
+	}
+	e := challenge(XOnly(R), XOnly(bip340.OutputKey), bytes)
+	return e, new(big.Int).Set(R.X), nil
+}
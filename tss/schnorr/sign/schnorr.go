@@ -0,0 +1,71 @@
+package sign
+
+import (
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss/schnorrsign"
+)
+
+var (
+	curve = secp256k1.S256()
+)
+
+// SchnorrSign BIP340 threshold signature over secp256k1, three rounds of schnorrsign.Signer
+type SchnorrSign struct {
+	*schnorrsign.Signer
+	PublicKey *curves.ECPoint // group public key from dkg
+	OutputKey *curves.ECPoint // even y key the signature verifies against, taproot output key if tweaked
+}
+
+type (
+	Step1Data = schnorrsign.Step1Data
+	Step2Data = schnorrsign.Step2Data
+)
+
+// NewSchnorrSign sign with the untweaked group key, the secret is negated if PublicKey has odd y
+func NewSchnorrSign(deviceNumber, threshold int, partList []int, ShareI *big.Int, PublicKey *curves.ECPoint, message string) *SchnorrSign {
+	if PublicKey == nil {
+		return nil
+	}
+	bip340 := &SchnorrSign{
+		PublicKey: PublicKey,
+		OutputKey: evenY(PublicKey),
+	}
+	bip340.Signer = schnorrsign.NewSigner(curve, deviceNumber, threshold, partList, ShareI, message, schnorrsign.Scheme{
+		Challenge:   bip340.challenge,
+		NonceFactor: negFactor,
+		Encode:      bip340.encode,
+		Verify:      bip340.verify,
+	})
+	if bip340.Signer == nil {
+		return nil
+	}
+	bip340.MulKeyFactor(negFactor(PublicKey))
+	return bip340
+}
+
+// NewSchnorrSignWithTaproot key path spend of the BIP341 output key tweaked with merkleRoot, empty for no script tree
+// d = gQ*(gP*x + t), gP and gQ negate the internal and output key to even y, t is added by the smallest device number
+func NewSchnorrSignWithTaproot(deviceNumber, threshold int, partList []int, ShareI *big.Int, PublicKey *curves.ECPoint, merkleRoot []byte, message string) *SchnorrSign {
+	bip340 := NewSchnorrSign(deviceNumber, threshold, partList, ShareI, PublicKey, message)
+	if bip340 == nil {
+		return nil
+	}
+	Q, t, err := taprootTweak(PublicKey, merkleRoot)
+	if err != nil {
+		return nil
+	}
+	gQ := negFactor(Q)
+	bip340.MulKeyFactor(gQ)
+	bip340.AddOffset(new(big.Int).Mod(new(big.Int).Mul(t, gQ), curve.N))
+	bip340.OutputKey = evenY(Q)
+	return bip340
+}
+
+// SetEpoch KeyStep3Data.Epoch, sent in the step1 commitment and checked by every signer with tss.CheckEpoch
+func (bip340 *SchnorrSign) SetEpoch(epoch int) *SchnorrSign {
+	bip340.Signer.SetEpoch(epoch)
+	return bip340
+}
@@ -0,0 +1,171 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/key/dkg"
+)
+
+func TestSchnorr(t *testing.T) {
+	p1Data, p2Data, p3Data := keyGen()
+	hash := sha256.Sum256([]byte("hello"))
+	message := hex.EncodeToString(hash[:])
+	shares := map[int]*tss.KeyStep3Data{1: p1Data, 2: p2Data, 3: p3Data}
+
+	for _, partList := range [][]int{{1, 2}, {1, 3}, {2, 3}} {
+		a, b := shares[partList[0]], shares[partList[1]]
+		pa := NewSchnorrSign(a.Id, 2, partList, a.ShareI, a.PublicKey, message)
+		pb := NewSchnorrSign(b.Id, 2, partList, b.ShareI, b.PublicKey, message)
+		signature := sign(t, pa, pb, message)
+		if !Verify(XOnly(p1Data.PublicKey), hash[:], signature) {
+			t.Fatal("bip340 signature verify fail", partList)
+		}
+	}
+}
+
+func TestSchnorrTaproot(t *testing.T) {
+	p1Data, _, p3Data := keyGen()
+	hash := sha256.Sum256([]byte("hello"))
+	message := hex.EncodeToString(hash[:])
+	partList := []int{1, 3}
+
+	for _, merkleRoot := range [][]byte{nil, hash[:]} {
+		outputKey, err := TaprootOutputKey(p1Data.PublicKey, merkleRoot)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p1 := NewSchnorrSignWithTaproot(1, 2, partList, p1Data.ShareI, p1Data.PublicKey, merkleRoot, message)
+		p3 := NewSchnorrSignWithTaproot(3, 2, partList, p3Data.ShareI, p3Data.PublicKey, merkleRoot, message)
+		signature := sign(t, p1, p3, message)
+		if !Verify(XOnly(outputKey), hash[:], signature) {
+			t.Fatal("taproot signature verify fail")
+		}
+		if Verify(XOnly(p1Data.PublicKey), hash[:], signature) {
+			t.Fatal("signature must not verify against the internal key")
+		}
+	}
+	if NewSchnorrSignWithTaproot(1, 2, partList, p1Data.ShareI, p1Data.PublicKey, []byte{1}, message) != nil {
+		t.Fatal("merkle root length must be checked")
+	}
+}
+
+func TestSchnorrInvalidPartial(t *testing.T) {
+	p1Data, p2Data, _ := keyGen()
+	message := hex.EncodeToString([]byte("hello"))
+	partList := []int{1, 2}
+	p1 := NewSchnorrSignWithTaproot(1, 2, partList, p1Data.ShareI, p1Data.PublicKey, nil, message).SetSharePubKeyMap(p1Data.SharePubKeyMap)
+	p2 := NewSchnorrSignWithTaproot(2, 2, partList, p2Data.ShareI, p2Data.PublicKey, nil, message).SetSharePubKeyMap(p2Data.SharePubKeyMap)
+
+	p1Step1, _ := p1.SignStep1()
+	p2Step1, _ := p2.SignStep1()
+	p1Step2, _ := p1.SignStep2([]*tss.Message{p2Step1[1]})
+	p2Step2, _ := p2.SignStep2([]*tss.Message{p1Step1[2]})
+	s1, r, _ := p1.SignStep3([]*tss.Message{p2Step2[1]})
+	s2, _, _ := p2.SignStep3([]*tss.Message{p1Step2[2]})
+
+	if !p1.VerifyPartial(1, s1, r, message) || !p1.VerifyPartial(2, s2, r, message) {
+		t.Fatal("partial signature verify fail")
+	}
+	_, err := p1.Aggregate(map[int]*big.Int{1: s1, 2: new(big.Int).Add(s2, big.NewInt(1))})
+	invalidErr, ok := err.(*tss.InvalidPartialError)
+	if !ok || len(invalidErr.Ids) != 1 || invalidErr.Ids[0] != 2 {
+		t.Fatal("invalid partial signature of device 2 should be identified", err)
+	}
+}
+
+// TestBIP340Vectors verification vectors from BIP340 and the BIP86 output key
+func TestBIP340Vectors(t *testing.T) {
+	vectors := []struct {
+		publicKey, message, signature string
+		result                        bool
+	}{
+		{
+			"F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"E907831F80848D1069A5371B402410364BDF1C5F8307B0084C55F1CE2DCA821525F66A4A85EA8B71E482A74F382D2CE5EBEEE8FDB2172F477DF4900D310536C0",
+			true,
+		},
+		{
+			"DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+			"243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+			"6896BD60EEAE296DB48A229FF71DFE071BDE413E6D43F917DC8DCF8C78DE33418906D11AC976ABCCB20B091292BFF4EA897EFCB639EA871CFA95F6DE339E4B0A",
+			true,
+		},
+		{
+			"DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+			"243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+			"6896BD60EEAE296DB48A229FF71DFE071BDE413E6D43F917DC8DCF8C78DE33418906D11AC976ABCCB20B091292BFF4EA897EFCB639EA871CFA95F6DE339E4B0B",
+			false,
+		},
+	}
+	for i, v := range vectors {
+		publicKey, _ := hex.DecodeString(v.publicKey)
+		message, _ := hex.DecodeString(v.message)
+		signature, _ := hex.DecodeString(v.signature)
+		if Verify(publicKey, message, signature) != v.result {
+			t.Fatal("bip340 vector fail", i)
+		}
+	}
+
+	internalKey, _ := new(big.Int).SetString("cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115", 16)
+	P, err := LiftX(internalKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Q, err := TaprootOutputKey(P, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(XOnly(Q)) != "a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c" {
+		t.Fatal("bip86 output key mismatch")
+	}
+}
+
+func sign(t *testing.T, pa, pb *SchnorrSign, message string) []byte {
+	a, b := pa.DeviceNumber, pb.DeviceNumber
+	aStep1, _ := pa.SignStep1()
+	bStep1, _ := pb.SignStep1()
+	aStep2, err := pa.SignStep2([]*tss.Message{bStep1[a]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bStep2, err := pb.SignStep2([]*tss.Message{aStep1[b]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sa, r, err := pa.SignStep3([]*tss.Message{bStep2[a]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb, _, err := pb.SignStep3([]*tss.Message{aStep2[b]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	new(big.Int).Mod(new(big.Int).Add(sa, sb), curve.N).FillBytes(signature[32:])
+	return signature
+}
+
+func keyGen() (*tss.KeyStep3Data, *tss.KeyStep3Data, *tss.KeyStep3Data) {
+	setUp1 := dkg.NewSetUp(1, 3, curve)
+	setUp2 := dkg.NewSetUp(2, 3, curve)
+	setUp3 := dkg.NewSetUp(3, 3, curve)
+
+	msgs1_1, _ := setUp1.DKGStep1()
+	msgs2_1, _ := setUp2.DKGStep1()
+	msgs3_1, _ := setUp3.DKGStep1()
+
+	msgs1_2, _ := setUp1.DKGStep2([]*tss.Message{msgs2_1[1], msgs3_1[1]})
+	msgs2_2, _ := setUp2.DKGStep2([]*tss.Message{msgs1_1[2], msgs3_1[2]})
+	msgs3_2, _ := setUp3.DKGStep2([]*tss.Message{msgs1_1[3], msgs2_1[3]})
+
+	p1SaveData, _ := setUp1.DKGStep3([]*tss.Message{msgs2_2[1], msgs3_2[1]})
+	p2SaveData, _ := setUp2.DKGStep3([]*tss.Message{msgs1_2[2], msgs3_2[2]})
+	p3SaveData, _ := setUp3.DKGStep3([]*tss.Message{msgs1_2[3], msgs2_2[3]})
+	return p1SaveData, p2SaveData, p3SaveData
+}
@@ -0,0 +1,101 @@
+package schnorrsign
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// RiMap Ri = ki*G of every participant, decommitted in SignStep3
+func (s *Signer) RiMap() map[int]*curves.ECPoint {
+	riMap := make(map[int]*curves.ECPoint, len(s.riMap))
+	for id, Ri := range s.riMap {
+		riMap[id] = Ri
+	}
+	return riMap
+}
+
+// Aggregate combine si of all participants after SignStep3, key: device number
+// every si is checked with VerifyPartial, s = sum(si) mod n, bad devices are reported by tss.InvalidPartialError
+// returns the encoded signature, checked with the Scheme Verify
+func (s *Signer) Aggregate(partials map[int]*big.Int) ([]byte, error) {
+	if s.R == nil {
+		return nil, fmt.Errorf("round error, SignStep3 is not finished")
+	}
+	if len(partials) != len(s.partList) {
+		return nil, fmt.Errorf("partial signatures number error")
+	}
+	if s.sharePubKeyMap == nil {
+		return nil, fmt.Errorf("share public keys are not set")
+	}
+
+	sum := new(big.Int)
+	var invalid []int
+	for _, id := range s.partList {
+		si, ok := partials[id]
+		if !ok {
+			return nil, fmt.Errorf("missing partial signature of %d", id)
+		}
+		if !s.VerifyPartial(id, si, s.r, s.message) {
+			invalid = append(invalid, id)
+			continue
+		}
+		sum.Add(sum, si)
+	}
+	if len(invalid) > 0 {
+		return nil, &tss.InvalidPartialError{Ids: invalid}
+	}
+	sum.Mod(sum, s.curve.Params().N)
+
+	signature, err := s.scheme.Encode(s.R, sum)
+	if err != nil {
+		return nil, err
+	}
+	if !s.scheme.Verify(signature) {
+		return nil, fmt.Errorf("aggregated signature verify fail")
+	}
+	return signature, nil
+}
+
+// VerifyPartial si*G == g*Ri + e*Wi, r is returned by SignStep3, message in hex
+// Wi = lambda_i*Yi times the key factor, the smallest device number also carries the offset
+// Ri comes from SignStep3 and Yi from SetSharePubKeyMap
+func (s *Signer) VerifyPartial(i int, si, r *big.Int, message string) bool {
+	if s.R == nil || s.sharePubKeyMap == nil || si == nil || r == nil {
+		return false
+	}
+	N := s.curve.Params().N
+	if si.Sign() < 0 || si.Cmp(N) >= 0 || r.Cmp(s.r) != 0 || message != s.message {
+		return false
+	}
+	Yi, ok := s.sharePubKeyMap[i]
+	if !ok {
+		return false
+	}
+	Ri, ok := s.riMap[i]
+	if !ok {
+		return false
+	}
+
+	xList := make([]*big.Int, len(s.partList))
+	for k, x := range s.partList {
+		xList[k] = big.NewInt(int64(x))
+	}
+	lambda := vss.CalLagrangian(s.curve, big.NewInt(int64(i)), s.keyFactor, xList)
+	Wi := Yi.ScalarMult(lambda)
+	var err error
+	if s.offset != nil && i == s.minId() {
+		Wi, err = Wi.Add(curves.ScalarToPoint(s.curve, s.offset))
+		if err != nil {
+			return false
+		}
+	}
+	right, err := Ri.ScalarMult(s.nonceFactor(s.R)).Add(Wi.ScalarMult(s.e))
+	if err != nil {
+		return false
+	}
+	return curves.ScalarToPoint(s.curve, si).Equals(right)
+}
@@ -0,0 +1,57 @@
+package schnorrsign
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/commitment"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss"
+)
+
+type Step1Data struct {
+	C     commitment.Commitment
+	Epoch int
+}
+
+// SignStep1 p2p send Ri commitment
+func (s *Signer) SignStep1() (map[int]*tss.Message, error) {
+	if s.RoundNumber != 1 {
+		return nil, fmt.Errorf("round error")
+	}
+	ki := crypto.RandomNum(s.curve.Params().N)
+	if s.scheme.Nonce != nil {
+		var err error
+		ki, err = s.scheme.Nonce(s.wi)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.ki = ki
+	Ri := curves.ScalarToPoint(s.curve, s.ki)
+	// Ri commitment
+	cmt := commitment.NewCommitment(Ri.X, Ri.Y)
+	s.cmtD = cmt.Msg
+	s.RoundNumber = 2
+
+	out := make(map[int]*tss.Message, s.Threshold-1)
+	for _, i := range s.partList {
+		if i == s.DeviceNumber {
+			continue
+		}
+		// p2p send message
+		data := Step1Data{C: cmt.C, Epoch: s.epoch}
+		bytes, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		message := &tss.Message{
+			From: s.DeviceNumber,
+			To:   i,
+			Data: string(bytes),
+		}
+		out[i] = message
+	}
+	return out, nil
+}
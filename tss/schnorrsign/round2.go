@@ -1,4 +1,4 @@
-package sign
+package schnorrsign
 
 import (
 	"encoding/json"
@@ -15,17 +15,17 @@ type Step2Data struct {
 }
 
 // SignStep2
-func (ed25519 *Ed25519Sign) SignStep2(msgs []*tss.Message) (map[int]*tss.Message, error) {
-	if ed25519.RoundNumber != 2 {
+func (s *Signer) SignStep2(msgs []*tss.Message) (map[int]*tss.Message, error) {
+	if s.RoundNumber != 2 {
 		return nil, fmt.Errorf("round error")
 	}
-	if len(msgs) != (ed25519.Threshold - 1) {
+	if len(msgs) != (s.Threshold - 1) {
 		return nil, fmt.Errorf("messages number error")
 	}
 	// received step1 message from others
-	ed25519.CommitmentMap = make(map[int]commitment.Commitment, len(msgs))
+	s.CommitmentMap = make(map[int]commitment.Commitment, len(msgs))
 	for _, msg := range msgs {
-		if msg.To != ed25519.DeviceNumber {
+		if msg.To != s.DeviceNumber {
 			return nil, fmt.Errorf("message sending error")
 		}
 		var content Step1Data
@@ -33,23 +33,27 @@ func (ed25519 *Ed25519Sign) SignStep2(msgs []*tss.Message) (map[int]*tss.Message
 		if err != nil {
 			return nil, err
 		}
-		ed25519.CommitmentMap[msg.From] = content.C
+		err = tss.CheckEpoch(s.epoch, content.Epoch)
+		if err != nil {
+			return nil, err
+		}
+		s.CommitmentMap[msg.From] = content.C
 	}
 	// zk schnorr prove ki
-	uiG := curves.ScalarToPoint(curve, ed25519.ki)
-	proof, err := schnorr.Prove(ed25519.ki, uiG)
+	uiG := curves.ScalarToPoint(s.curve, s.ki)
+	proof, err := schnorr.Prove(s.ki, uiG)
 	if err != nil {
 		return nil, err
 	}
-	ed25519.RoundNumber = 3
+	s.RoundNumber = 3
 
-	out := make(map[int]*tss.Message, ed25519.Threshold-1)
-	for _, i := range ed25519.partList {
-		if i == ed25519.DeviceNumber {
+	out := make(map[int]*tss.Message, s.Threshold-1)
+	for _, i := range s.partList {
+		if i == s.DeviceNumber {
 			continue
 		}
 		data := Step2Data{
-			Witness: ed25519.cmtD,
+			Witness: s.cmtD,
 			Proof:   proof,
 		}
 		bytes, err := json.Marshal(data)
@@ -57,7 +61,7 @@ func (ed25519 *Ed25519Sign) SignStep2(msgs []*tss.Message) (map[int]*tss.Message
 			return nil, err
 		}
 		message := &tss.Message{
-			From: ed25519.DeviceNumber,
+			From: s.DeviceNumber,
 			To:   i,
 			Data: string(bytes),
 		}
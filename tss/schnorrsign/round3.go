@@ -0,0 +1,86 @@
+package schnorrsign
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/commitment"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/schnorr"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// SignStep3  calculate R, si = g*ki + e*wi, e and g are given by the Scheme
+// returns si and r, the encoding of R
+func (s *Signer) SignStep3(msgs []*tss.Message) (*big.Int, *big.Int, error) {
+	if s.RoundNumber != 3 {
+		return nil, nil, fmt.Errorf("round error")
+	}
+	s.RoundNumber = -1
+	if len(msgs) != (s.Threshold - 1) {
+		return nil, nil, fmt.Errorf("messages number error")
+	}
+	// R = sum(Ri)
+	R := curves.ScalarToPoint(s.curve, s.ki)
+	riMap := make(map[int]*curves.ECPoint, s.Threshold)
+	riMap[s.DeviceNumber] = R
+	for _, msg := range msgs {
+		if msg.To != s.DeviceNumber {
+			return nil, nil, fmt.Errorf("message sending error")
+		}
+		var data Step2Data
+		err := json.Unmarshal([]byte(msg.Data), &data)
+		if err != nil {
+			return nil, nil, err
+		}
+		// check Ri commitment
+		commit := commitment.HashCommitment{}
+		commit.C = s.CommitmentMap[msg.From]
+		commit.Msg = data.Witness
+		ok, DeC := commit.Open()
+		if !ok {
+			return nil, nil, fmt.Errorf("commitment DeCommit fail")
+		}
+		Rj, err := curves.NewECPoint(s.curve, DeC[0], DeC[1])
+		if err != nil {
+			return nil, nil, err
+		}
+		// ki schnorr verify, Rj = kj*G
+		verify := schnorr.Verify(data.Proof, Rj)
+		if !verify {
+			return nil, nil, fmt.Errorf("schnorr verify fail")
+		}
+		if _, ok := riMap[msg.From]; ok {
+			return nil, nil, fmt.Errorf("duplicate message from %d", msg.From)
+		}
+		riMap[msg.From] = Rj
+		R, err = R.Add(Rj)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	e, r, err := s.scheme.Challenge(R)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// si = g*ki + e*wi
+	N := s.curve.Params().N
+	si := new(big.Int).Mul(e, s.wi)
+	si.Add(si, new(big.Int).Mul(s.nonceFactor(R), s.ki))
+	si.Mod(si, N)
+
+	s.R = R
+	s.riMap = riMap
+	s.e = e
+	s.r = r
+	return si, new(big.Int).Set(r), nil
+}
+
+func (s *Signer) nonceFactor(R *curves.ECPoint) *big.Int {
+	if s.scheme.NonceFactor == nil {
+		return big.NewInt(1)
+	}
+	return s.scheme.NonceFactor(R)
+}
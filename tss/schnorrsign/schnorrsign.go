@@ -0,0 +1,140 @@
+// Package schnorrsign three signing rounds shared by the Ed25519, BIP340 and sr25519 threshold signatures
+// the signatures differ only in the nonce, the challenge and the encoding, which are given by a Scheme
+package schnorrsign
+
+import (
+	"crypto/elliptic"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/commitment"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+)
+
+// Scheme signature specific functions, si = g*ki + e*wi with e = Challenge(R) and g = NonceFactor(R)
+type Scheme struct {
+	// Nonce ki of SignStep1 from the weighted share wi, random if nil
+	Nonce func(wi *big.Int) (*big.Int, error)
+	// Challenge e of R = sum(Ri) and r, the encoding of R returned by SignStep3
+	Challenge func(R *curves.ECPoint) (e, r *big.Int, err error)
+	// NonceFactor 1 or n-1, negates the nonces if R must be normalized, 1 if nil
+	NonceFactor func(R *curves.ECPoint) *big.Int
+	// Encode signature of R and s = sum(si)
+	Encode func(R *curves.ECPoint, s *big.Int) ([]byte, error)
+	// Verify the encoded signature against the message and the public key
+	Verify func(signature []byte) bool
+}
+
+// Signer state of one participant, embedded by the signature types
+type Signer struct {
+	DeviceNumber int
+	Threshold    int
+	RoundNumber  int
+	R            *curves.ECPoint // R = sum(Ri), available after SignStep3
+
+	curve    elliptic.Curve
+	scheme   Scheme
+	partList []int // participating signature number, usually 2
+	wi       *big.Int
+	ki       *big.Int
+	message  string
+	epoch    int // key share epoch, all participants must use the same
+
+	keyFactor      *big.Int                // multiplies the lagrange coefficients, 1 unless the key is negated
+	offset         *big.Int                // added to the secret by the smallest device number
+	sharePubKeyMap map[int]*curves.ECPoint // ShareI*G map, used to verify partial signatures
+	riMap          map[int]*curves.ECPoint // Ri = ki*G of every participant, available after SignStep3
+	r              *big.Int                // encoding of R, available after SignStep3
+	e              *big.Int                // challenge, available after SignStep3
+
+	cmtD          commitment.Witness
+	CommitmentMap map[int]commitment.Commitment
+}
+
+// NewSigner wi = lambda_i*ShareI, the lagrange weighted share of deviceNumber in partList
+func NewSigner(curve elliptic.Curve, deviceNumber, threshold int, partList []int, ShareI *big.Int, message string, scheme Scheme) *Signer {
+	if len(partList) != threshold || ShareI == nil {
+		return nil
+	}
+	xList := make([]*big.Int, len(partList))
+	for i, x := range partList {
+		xList[i] = big.NewInt(int64(x))
+	}
+	// lagrangian interpolation wi
+	wi := vss.CalLagrangian(curve, big.NewInt(int64(deviceNumber)), ShareI, xList)
+
+	return &Signer{
+		DeviceNumber: deviceNumber,
+		Threshold:    threshold,
+		RoundNumber:  1,
+		curve:        curve,
+		scheme:       scheme,
+		partList:     partList,
+		wi:           wi,
+		message:      message,
+		keyFactor:    big.NewInt(1),
+	}
+}
+
+// MulKeyFactor wi = factor*wi, factor is 1 or n-1 to negate the key
+func (s *Signer) MulKeyFactor(factor *big.Int) {
+	N := s.curve.Params().N
+	s.wi = new(big.Int).Mod(new(big.Int).Mul(s.wi, factor), N)
+	s.keyFactor = new(big.Int).Mod(new(big.Int).Mul(s.keyFactor, factor), N)
+	if s.offset != nil {
+		s.offset = new(big.Int).Mod(new(big.Int).Mul(s.offset, factor), N)
+	}
+}
+
+// AddOffset child key or taproot tweak, added to wi by the smallest device number in partList only
+func (s *Signer) AddOffset(offset *big.Int) {
+	N := s.curve.Params().N
+	if s.DeviceNumber == s.minId() {
+		s.wi = new(big.Int).Mod(new(big.Int).Add(s.wi, offset), N)
+	}
+	if s.offset == nil {
+		s.offset = new(big.Int)
+	}
+	s.offset = new(big.Int).Mod(new(big.Int).Add(s.offset, offset), N)
+}
+
+// SetEpoch KeyStep3Data.Epoch, sent in the step1 commitment and checked by every signer with tss.CheckEpoch
+func (s *Signer) SetEpoch(epoch int) {
+	s.epoch = epoch
+}
+
+// SetSharePubKeyMap ShareI*G map from KeyStep3Data, required to verify partial signatures
+func (s *Signer) SetSharePubKeyMap(sharePubKeyMap map[int]*curves.ECPoint) {
+	s.sharePubKeyMap = sharePubKeyMap
+}
+
+// ClaimNonceId refuse a nonce id this share already used, see crypto.ClaimNonceId
+func (s *Signer) ClaimNonceId(label string, nonceId []byte) error {
+	return crypto.ClaimNonceId(s.wi, label, nonceId)
+}
+
+// Epoch key share epoch set by SetEpoch
+func (s *Signer) Epoch() int {
+	return s.epoch
+}
+
+// PartList participating device numbers
+func (s *Signer) PartList() []int {
+	return append([]int{}, s.partList...)
+}
+
+// Message hex message to sign
+func (s *Signer) Message() string {
+	return s.message
+}
+
+func (s *Signer) minId() int {
+	min := s.partList[0]
+	for _, id := range s.partList {
+		if id < min {
+			min = id
+		}
+	}
+	return min
+}
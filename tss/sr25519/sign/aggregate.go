@@ -0,0 +1,35 @@
+package sign
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+)
+
+// SetSharePubKeyMap ShareI*G map from KeyStep3Data, required to verify partial signatures
+func (sr25519 *Sr25519Sign) SetSharePubKeyMap(sharePubKeyMap map[int]*curves.ECPoint) *Sr25519Sign {
+	sr25519.Signer.SetSharePubKeyMap(sharePubKeyMap)
+	return sr25519
+}
+
+// encode 64-byte sr25519 signature R || s with the schnorrkel marker bit
+func encode(R *curves.ECPoint, s *big.Int) ([]byte, error) {
+	RBytes, err := R.Encode(true)
+	if err != nil {
+		return nil, err
+	}
+	signature := make([]byte, 64)
+	copy(signature, RBytes)
+	copy(signature[32:], scalarToLE(s))
+	signature[63] |= 0x80
+	return signature, nil
+}
+
+func (sr25519 *Sr25519Sign) verify(signature []byte) bool {
+	bytes, err := hex.DecodeString(sr25519.Message())
+	if err != nil {
+		return false
+	}
+	return Verify(sr25519.publicKey, sr25519.context, bytes, signature)
+}
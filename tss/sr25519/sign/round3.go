@@ -0,0 +1,17 @@
+package sign
+
+import (
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+)
+
+// challenge k of the transcript and R, and r, the ristretto255 encoding of R as a little endian integer
+// SignStep3 returns si = ki + k*wi
+func (sr25519 *Sr25519Sign) challenge(R *curves.ECPoint) (*big.Int, *big.Int, error) {
+	RBytes, err := R.Encode(true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return challenge(sr25519.transcript, RBytes), leToInt(RBytes), nil
+}
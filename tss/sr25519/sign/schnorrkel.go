@@ -0,0 +1,88 @@
+package sign
+
+import (
+	cryptorand "crypto/rand"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/merlin"
+)
+
+// signingTranscript schnorrkel signing_context(context).bytes(message) with the public key committed
+func signingTranscript(context, message, publicKey []byte) *merlin.Transcript {
+	t := merlin.NewTranscript("SigningContext")
+	t.AppendMessage([]byte(""), context)
+	t.AppendMessage([]byte("sign-bytes"), message)
+	t.AppendMessage([]byte("proto-name"), []byte("Schnorr-sig"))
+	t.AppendMessage([]byte("sign:pk"), publicKey)
+	return t
+}
+
+// challenge k = H(transcript || R), 64 challenge bytes reduced mod l
+func challenge(t *merlin.Transcript, R []byte) *big.Int {
+	t = t.Clone()
+	t.AppendMessage([]byte("sign:R"), R)
+	return leToScalar(t.ChallengeBytes([]byte("sign:c"), 64))
+}
+
+// witnessScalar schnorrkel witness_scalar, nonce from the transcript, the secret share and fresh randomness
+func witnessScalar(t *merlin.Transcript, secret *big.Int) (*big.Int, error) {
+	rand := make([]byte, 32)
+	if _, err := cryptorand.Read(rand); err != nil {
+		return nil, err
+	}
+	return leToScalar(t.WitnessBytes([]byte("signing"), 64, [][]byte{scalarToLE(secret)}, rand)), nil
+}
+
+// Verify standard sr25519 verification, publicKey is the 32-byte ristretto255 encoding
+// signature R || s with the schnorrkel marker bit set in the last byte
+func Verify(publicKey, context, message, signature []byte) bool {
+	if len(signature) != 64 || signature[63]&0x80 == 0 {
+		return false
+	}
+	A, err := curves.DecodePoint(curve, publicKey)
+	if err != nil {
+		return false
+	}
+	sBytes := make([]byte, 32)
+	copy(sBytes, signature[32:])
+	sBytes[31] &= 0x7f
+	s := new(big.Int).SetBytes(reverse(sBytes))
+	if s.Cmp(curve.Params().N) >= 0 {
+		return false
+	}
+	k := challenge(signingTranscript(context, message, publicKey), signature[:32])
+
+	// R = s*G - k*A
+	sG := curves.ScalarToPoint(curve, s)
+	kA := A.ScalarMult(new(big.Int).Sub(curve.Params().N, k))
+	x, y := curve.Add(sG.X, sG.Y, kA.X, kA.Y)
+	R := &curves.ECPoint{Curve: curve, X: x, Y: y}
+	encoded, err := R.Encode(true)
+	if err != nil {
+		return false
+	}
+	return string(encoded) == string(signature[:32])
+}
+
+// scalarToLE 32-byte little endian scalar
+func scalarToLE(s *big.Int) []byte {
+	b := make([]byte, 32)
+	new(big.Int).Mod(s, curve.Params().N).FillBytes(b)
+	return reverse(b)
+}
+
+func leToScalar(b []byte) *big.Int {
+	return new(big.Int).Mod(leToInt(b), curve.Params().N)
+}
+
+func leToInt(b []byte) *big.Int {
+	return new(big.Int).SetBytes(reverse(append([]byte{}, b...)))
+}
+
+func reverse(b []byte) []byte {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
@@ -0,0 +1,69 @@
+package sign
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/merlin"
+	"github.com/okx/threshold-lib/tss/schnorrsign"
+)
+
+var (
+	curve = curves.RistrettoCurve()
+)
+
+// Sr25519Sign threshold schnorrkel signature over ristretto255, three rounds of schnorrsign.Signer
+type Sr25519Sign struct {
+	*schnorrsign.Signer
+	PublicKey *curves.ECPoint // group public key from dkg over ristretto255
+	context   []byte          // signing context, "substrate" for Substrate extrinsics
+
+	publicKey  []byte             // ristretto255 encoding of PublicKey
+	transcript *merlin.Transcript // signing transcript up to the public key
+}
+
+type (
+	Step1Data = schnorrsign.Step1Data
+	Step2Data = schnorrsign.Step2Data
+)
+
+// NewSr25519Sign sign message in hex under the signing context, PublicKey must be on the ristretto255 curve
+func NewSr25519Sign(deviceNumber, threshold int, partList []int, ShareI *big.Int, PublicKey *curves.ECPoint, context []byte, message string) *Sr25519Sign {
+	if PublicKey == nil || curves.GetCurveName(PublicKey.Curve) != curves.Ristretto255 {
+		return nil
+	}
+	publicKey, err := PublicKey.Encode(true)
+	if err != nil {
+		return nil
+	}
+	msg, err := hex.DecodeString(message)
+	if err != nil {
+		return nil
+	}
+	sr25519 := &Sr25519Sign{
+		PublicKey:  PublicKey,
+		context:    context,
+		publicKey:  publicKey,
+		transcript: signingTranscript(context, msg, publicKey),
+	}
+	sr25519.Signer = schnorrsign.NewSigner(curve, deviceNumber, threshold, partList, ShareI, message, schnorrsign.Scheme{
+		// ki from the transcript, the share and fresh randomness, like schnorrkel
+		Nonce: func(wi *big.Int) (*big.Int, error) {
+			return witnessScalar(sr25519.transcript, wi)
+		},
+		Challenge: sr25519.challenge,
+		Encode:    encode,
+		Verify:    sr25519.verify,
+	})
+	if sr25519.Signer == nil {
+		return nil
+	}
+	return sr25519
+}
+
+// SetEpoch KeyStep3Data.Epoch of the signing share, a step1 message of another epoch fails tss.CheckEpoch
+func (sr25519 *Sr25519Sign) SetEpoch(epoch int) *Sr25519Sign {
+	sr25519.Signer.SetEpoch(epoch)
+	return sr25519
+}
@@ -0,0 +1,122 @@
+package sign
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/key/dkg"
+)
+
+var substrate = []byte("substrate")
+
+func TestSr25519(t *testing.T) {
+	p1Data, p2Data, p3Data := keyGen()
+	message := hex.EncodeToString([]byte("this is a message"))
+	shares := map[int]*tss.KeyStep3Data{1: p1Data, 2: p2Data, 3: p3Data}
+	publicKey, err := p1Data.PublicKey.Encode(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, partList := range [][]int{{1, 2}, {1, 3}, {2, 3}} {
+		a, b := shares[partList[0]], shares[partList[1]]
+		pa := NewSr25519Sign(a.Id, 2, partList, a.ShareI, a.PublicKey, substrate, message).SetSharePubKeyMap(a.SharePubKeyMap)
+		pb := NewSr25519Sign(b.Id, 2, partList, b.ShareI, b.PublicKey, substrate, message).SetSharePubKeyMap(b.SharePubKeyMap)
+		signature := sign(t, pa, pb)
+		if !Verify(publicKey, substrate, []byte("this is a message"), signature) {
+			t.Fatal("sr25519 signature verify fail", partList)
+		}
+		if Verify(publicKey, []byte("other context"), []byte("this is a message"), signature) {
+			t.Fatal("signature must be bound to the context")
+		}
+	}
+}
+
+func TestSr25519InvalidPartial(t *testing.T) {
+	p1Data, p2Data, _ := keyGen()
+	message := hex.EncodeToString([]byte("hello"))
+	partList := []int{1, 2}
+	p1 := NewSr25519Sign(1, 2, partList, p1Data.ShareI, p1Data.PublicKey, substrate, message).SetSharePubKeyMap(p1Data.SharePubKeyMap)
+	p2 := NewSr25519Sign(2, 2, partList, p2Data.ShareI, p2Data.PublicKey, substrate, message).SetSharePubKeyMap(p2Data.SharePubKeyMap)
+
+	p1Step1, _ := p1.SignStep1()
+	p2Step1, _ := p2.SignStep1()
+	p1Step2, _ := p1.SignStep2([]*tss.Message{p2Step1[1]})
+	p2Step2, _ := p2.SignStep2([]*tss.Message{p1Step1[2]})
+	s1, r, _ := p1.SignStep3([]*tss.Message{p2Step2[1]})
+	s2, _, _ := p2.SignStep3([]*tss.Message{p1Step2[2]})
+
+	if !p1.VerifyPartial(1, s1, r, message) || !p1.VerifyPartial(2, s2, r, message) {
+		t.Fatal("partial signature verify fail")
+	}
+	_, err := p1.Aggregate(map[int]*big.Int{1: s1, 2: new(big.Int).Add(s2, big.NewInt(1))})
+	invalidErr, ok := err.(*tss.InvalidPartialError)
+	if !ok || len(invalidErr.Ids) != 1 || invalidErr.Ids[0] != 2 {
+		t.Fatal("invalid partial signature of device 2 should be identified", err)
+	}
+	if NewSr25519Sign(1, 2, partList, p1Data.ShareI, nil, substrate, message) != nil {
+		t.Fatal("public key must be checked")
+	}
+}
+
+// TestSr25519Vector signature made by schnorrkel, from the polkadot-js sr25519 tests
+func TestSr25519Vector(t *testing.T) {
+	publicKey, _ := hex.DecodeString("46ebddef8cd9bb167dc30878d7113b7e168e6f0646beffd77d69d39bad76b47a")
+	signature, _ := hex.DecodeString("4e172314444b8f820bb54c22e95076f220ed25373e5c178234aa6c211d29271244b947e3ff3418ff6b45fd1df1140c8cbff69fc58ee6dc96df70936a2bb74b82")
+	message := []byte("this is a message")
+	if !Verify(publicKey, substrate, message, signature) {
+		t.Fatal("sr25519 vector verify fail")
+	}
+	signature[40] ^= 1
+	if Verify(publicKey, substrate, message, signature) {
+		t.Fatal("modified signature must not verify")
+	}
+}
+
+func sign(t *testing.T, pa, pb *Sr25519Sign) []byte {
+	a, b := pa.DeviceNumber, pb.DeviceNumber
+	aStep1, _ := pa.SignStep1()
+	bStep1, _ := pb.SignStep1()
+	aStep2, err := pa.SignStep2([]*tss.Message{bStep1[a]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bStep2, err := pb.SignStep2([]*tss.Message{aStep1[b]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sa, _, err := pa.SignStep3([]*tss.Message{bStep2[a]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb, _, err := pb.SignStep3([]*tss.Message{aStep2[b]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := pa.Aggregate(map[int]*big.Int{a: sa, b: sb})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signature
+}
+
+func keyGen() (*tss.KeyStep3Data, *tss.KeyStep3Data, *tss.KeyStep3Data) {
+	setUp1 := dkg.NewSetUp(1, 3, curve)
+	setUp2 := dkg.NewSetUp(2, 3, curve)
+	setUp3 := dkg.NewSetUp(3, 3, curve)
+
+	msgs1_1, _ := setUp1.DKGStep1()
+	msgs2_1, _ := setUp2.DKGStep1()
+	msgs3_1, _ := setUp3.DKGStep1()
+
+	msgs1_2, _ := setUp1.DKGStep2([]*tss.Message{msgs2_1[1], msgs3_1[1]})
+	msgs2_2, _ := setUp2.DKGStep2([]*tss.Message{msgs1_1[2], msgs3_1[2]})
+	msgs3_2, _ := setUp3.DKGStep2([]*tss.Message{msgs1_1[3], msgs2_1[3]})
+
+	p1SaveData, _ := setUp1.DKGStep3([]*tss.Message{msgs2_2[1], msgs3_2[1]})
+	p2SaveData, _ := setUp2.DKGStep3([]*tss.Message{msgs1_2[2], msgs3_2[2]})
+	p3SaveData, _ := setUp3.DKGStep3([]*tss.Message{msgs1_2[3], msgs2_2[3]})
+	return p1SaveData, p2SaveData, p3SaveData
+}